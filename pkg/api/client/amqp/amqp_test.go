@@ -3,6 +3,7 @@ package amqp_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
@@ -107,3 +108,65 @@ func TestRunning(t *testing.T) {
 	testPhases(runningPhases, true)
 	testPhases(notRunningPhases, false)
 }
+
+// Testing AmqpClientCommon WaitForStatus method, making sure status
+// transitions are observed and that it times out when status is not reached.
+func TestWaitForStatus(t *testing.T) {
+	pod := v1.Pod{
+		Status:     v1.PodStatus{Phase: v1.PodPending},
+		ObjectMeta: metav1.ObjectMeta{Name: "WaitPod", Namespace: "TheNamespace"},
+	}
+	client := &amqp.AmqpClientCommon{
+		Name: "WaitClient",
+		Pod:  &pod,
+		Context: framework.ContextData{
+			Namespace: "TheNamespace",
+			Clients: framework.ClientSet{
+				KubeClient: fake.NewSimpleClientset(&pod),
+			},
+		},
+	}
+
+	// Not reached before timeout
+	if s := client.WaitForStatus(1, amqp.Success); s != amqp.Timeout {
+		t.Errorf("WaitForStatus returned %v, expected %v", s, amqp.Timeout)
+	}
+
+	// Already reached
+	if s := client.WaitForStatus(testTimeout, amqp.Starting, amqp.Running); s != amqp.Starting {
+		t.Errorf("WaitForStatus returned %v, expected %v", s, amqp.Starting)
+	}
+
+	// Pod phase changes while waiting
+	result := make(chan amqp.ClientStatus, 1)
+	go func() {
+		result <- client.WaitFor(testTimeout)
+	}()
+	pod.Status.Phase = v1.PodSucceeded
+	for done := false; !done; {
+		if _, err := client.Context.Clients.KubeClient.CoreV1().Pods(client.Context.Namespace).Update(context.TODO(), &pod, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("error updating pod: %v", err)
+		}
+		select {
+		case s := <-result:
+			if s != amqp.Success {
+				t.Errorf("WaitFor returned %v, expected %v", s, amqp.Success)
+			}
+			done = true
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// Client interrupted while waiting
+	pod.Status.Phase = v1.PodRunning
+	if _, err := client.Context.Clients.KubeClient.CoreV1().Pods(client.Context.Namespace).Update(context.TODO(), &pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("error updating pod: %v", err)
+	}
+	go func() {
+		result <- client.WaitFor(testTimeout)
+	}()
+	client.Interrupt()
+	if s := <-result; s != amqp.Interrupted {
+		t.Errorf("WaitFor returned %v, expected %v", s, amqp.Interrupted)
+	}
+}
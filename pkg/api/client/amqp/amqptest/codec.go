@@ -0,0 +1,470 @@
+package amqptest
+
+//
+// Minimal AMQP 1.0 type system encoder and decoder, covering just what is
+// needed to exchange performatives with an AMQP 1.0 client.
+//
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// symbol represents an AMQP symbol (as opposed to an AMQP string)
+type symbol string
+
+// symbols is encoded as an array of symbols
+type symbols []symbol
+
+// timestamp represents an AMQP timestamp (milliseconds since unix epoch)
+type timestamp int64
+
+// described represents an AMQP described type, like performatives,
+// delivery states, sources, targets and errors
+type described struct {
+	descriptor interface{}
+	value      interface{}
+}
+
+// code returns the numeric descriptor of a described type or
+// math.MaxUint64 when the descriptor is not numeric
+func (d described) code() uint64 {
+	if c, ok := d.descriptor.(uint64); ok {
+		return c
+	}
+	return math.MaxUint64
+}
+
+// field returns the i-th field of a described list (composite type) or
+// nil if the value is not a list or the field is not present
+func (d described) field(i int) interface{} {
+	fields, ok := d.value.([]interface{})
+	if !ok || i >= len(fields) {
+		return nil
+	}
+	return fields[i]
+}
+
+// Typed wrappers used by the encoder
+type (
+	uByte  uint8
+	uShort uint16
+	uInt   uint32
+	uLong  uint64
+)
+
+//
+// Decoder
+//
+
+type decoder struct {
+	buf *bytes.Reader
+}
+
+func newDecoder(data []byte) *decoder {
+	return &decoder{buf: bytes.NewReader(data)}
+}
+
+// remaining returns the bytes not yet consumed by the decoder
+func (d *decoder) remaining() []byte {
+	rest := make([]byte, d.buf.Len())
+	_, _ = d.buf.Read(rest)
+	return rest
+}
+
+func (d *decoder) readN(n int) ([]byte, error) {
+	if n < 0 || n > d.buf.Len() {
+		return nil, fmt.Errorf("invalid length %d (remaining: %d)", n, d.buf.Len())
+	}
+	b := make([]byte, n)
+	_, err := d.buf.Read(b)
+	return b, err
+}
+
+func (d *decoder) readUint8() (uint8, error) {
+	return d.buf.ReadByte()
+}
+
+func (d *decoder) readUint16() (uint16, error) {
+	b, err := d.readN(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
+
+func (d *decoder) readUint32() (uint32, error) {
+	b, err := d.readN(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+func (d *decoder) readUint64() (uint64, error) {
+	b, err := d.readN(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// readValue reads the next constructor and its value
+func (d *decoder) readValue() (interface{}, error) {
+	code, err := d.readUint8()
+	if err != nil {
+		return nil, err
+	}
+	return d.readTyped(code)
+}
+
+// readTyped reads a value for the given constructor code
+func (d *decoder) readTyped(code uint8) (interface{}, error) {
+	switch code {
+	case 0x00:
+		descriptor, err := d.readValue()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.readValue()
+		if err != nil {
+			return nil, err
+		}
+		return described{descriptor: descriptor, value: value}, nil
+	case 0x40:
+		return nil, nil
+	case 0x41:
+		return true, nil
+	case 0x42:
+		return false, nil
+	case 0x56:
+		b, err := d.readUint8()
+		return b != 0, err
+	case 0x50:
+		return d.readUint8()
+	case 0x51:
+		b, err := d.readUint8()
+		return int8(b), err
+	case 0x60:
+		return d.readUint16()
+	case 0x61:
+		v, err := d.readUint16()
+		return int16(v), err
+	case 0x43:
+		return uint32(0), nil
+	case 0x52:
+		b, err := d.readUint8()
+		return uint32(b), err
+	case 0x70:
+		return d.readUint32()
+	case 0x54:
+		b, err := d.readUint8()
+		return int32(int8(b)), err
+	case 0x71:
+		v, err := d.readUint32()
+		return int32(v), err
+	case 0x44:
+		return uint64(0), nil
+	case 0x53:
+		b, err := d.readUint8()
+		return uint64(b), err
+	case 0x80:
+		return d.readUint64()
+	case 0x55:
+		b, err := d.readUint8()
+		return int64(int8(b)), err
+	case 0x81:
+		v, err := d.readUint64()
+		return int64(v), err
+	case 0x72:
+		v, err := d.readUint32()
+		return math.Float32frombits(v), err
+	case 0x82:
+		v, err := d.readUint64()
+		return math.Float64frombits(v), err
+	case 0x73:
+		v, err := d.readUint32()
+		return rune(v), err
+	case 0x83:
+		v, err := d.readUint64()
+		return timestamp(v), err
+	case 0x98:
+		b, err := d.readN(16)
+		if err != nil {
+			return nil, err
+		}
+		var uuid [16]byte
+		copy(uuid[:], b)
+		return uuid, nil
+	case 0xa0, 0xa1, 0xa3:
+		n, err := d.readUint8()
+		if err != nil {
+			return nil, err
+		}
+		return d.readVariable(code, int(n))
+	case 0xb0, 0xb1, 0xb3:
+		n, err := d.readUint32()
+		if err != nil {
+			return nil, err
+		}
+		return d.readVariable(code, int(n))
+	case 0x45:
+		return []interface{}{}, nil
+	case 0xc0, 0xc1:
+		if _, err := d.readUint8(); err != nil {
+			return nil, err
+		}
+		count, err := d.readUint8()
+		if err != nil {
+			return nil, err
+		}
+		return d.readCompound(code == 0xc1, int(count))
+	case 0xd0, 0xd1:
+		if _, err := d.readUint32(); err != nil {
+			return nil, err
+		}
+		count, err := d.readUint32()
+		if err != nil {
+			return nil, err
+		}
+		return d.readCompound(code == 0xd1, int(count))
+	case 0xe0:
+		if _, err := d.readUint8(); err != nil {
+			return nil, err
+		}
+		count, err := d.readUint8()
+		if err != nil {
+			return nil, err
+		}
+		return d.readArray(int(count))
+	case 0xf0:
+		if _, err := d.readUint32(); err != nil {
+			return nil, err
+		}
+		count, err := d.readUint32()
+		if err != nil {
+			return nil, err
+		}
+		return d.readArray(int(count))
+	default:
+		return nil, fmt.Errorf("unsupported amqp type constructor 0x%02x", code)
+	}
+}
+
+func (d *decoder) readVariable(code uint8, n int) (interface{}, error) {
+	b, err := d.readN(n)
+	if err != nil {
+		return nil, err
+	}
+	switch code {
+	case 0xa1, 0xb1:
+		return string(b), nil
+	case 0xa3, 0xb3:
+		return symbol(b), nil
+	default:
+		return b, nil
+	}
+}
+
+func (d *decoder) readCompound(isMap bool, count int) (interface{}, error) {
+	items := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		v, err := d.readValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	if !isMap {
+		return items, nil
+	}
+	m := map[interface{}]interface{}{}
+	for i := 0; i+1 < len(items); i += 2 {
+		switch items[i].(type) {
+		case []byte, []interface{}, map[interface{}]interface{}, described:
+			// non comparable keys are not expected on performatives
+			continue
+		}
+		m[items[i]] = items[i+1]
+	}
+	return m, nil
+}
+
+func (d *decoder) readArray(count int) (interface{}, error) {
+	code, err := d.readUint8()
+	if err != nil {
+		return nil, err
+	}
+	var descriptor interface{}
+	if code == 0x00 {
+		if descriptor, err = d.readValue(); err != nil {
+			return nil, err
+		}
+		if code, err = d.readUint8(); err != nil {
+			return nil, err
+		}
+	}
+	items := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		v, err := d.readTyped(code)
+		if err != nil {
+			return nil, err
+		}
+		if descriptor != nil {
+			v = described{descriptor: descriptor, value: v}
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+//
+// Encoder
+//
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) bytes() []byte {
+	return e.buf.Bytes()
+}
+
+func (e *encoder) writeUint16(v uint16) {
+	_ = binary.Write(&e.buf, binary.BigEndian, v)
+}
+
+func (e *encoder) writeUint32(v uint32) {
+	_ = binary.Write(&e.buf, binary.BigEndian, v)
+}
+
+func (e *encoder) writeUint64(v uint64) {
+	_ = binary.Write(&e.buf, binary.BigEndian, v)
+}
+
+// writeValue encodes the given value using the smallest possible encoding
+func (e *encoder) writeValue(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		e.buf.WriteByte(0x40)
+	case bool:
+		if v {
+			e.buf.WriteByte(0x41)
+		} else {
+			e.buf.WriteByte(0x42)
+		}
+	case uByte:
+		e.buf.Write([]byte{0x50, byte(v)})
+	case uShort:
+		e.buf.WriteByte(0x60)
+		e.writeUint16(uint16(v))
+	case uInt:
+		switch {
+		case v == 0:
+			e.buf.WriteByte(0x43)
+		case v < 256:
+			e.buf.Write([]byte{0x52, byte(v)})
+		default:
+			e.buf.WriteByte(0x70)
+			e.writeUint32(uint32(v))
+		}
+	case uLong:
+		switch {
+		case v == 0:
+			e.buf.WriteByte(0x44)
+		case v < 256:
+			e.buf.Write([]byte{0x53, byte(v)})
+		default:
+			e.buf.WriteByte(0x80)
+			e.writeUint64(uint64(v))
+		}
+	case string:
+		e.writeVariable(0xa1, 0xb1, []byte(v))
+	case symbol:
+		e.writeVariable(0xa3, 0xb3, []byte(v))
+	case []byte:
+		e.writeVariable(0xa0, 0xb0, v)
+	case symbols:
+		e.writeSymbols(v)
+	case []interface{}:
+		return e.writeList(v)
+	case map[interface{}]interface{}:
+		return e.writeMap(v)
+	case described:
+		e.buf.WriteByte(0x00)
+		if err := e.writeValue(v.descriptor); err != nil {
+			return err
+		}
+		return e.writeValue(v.value)
+	default:
+		return fmt.Errorf("unable to encode value of type %T", value)
+	}
+	return nil
+}
+
+func (e *encoder) writeVariable(code8, code32 uint8, b []byte) {
+	if len(b) < 256 {
+		e.buf.Write([]byte{code8, byte(len(b))})
+	} else {
+		e.buf.WriteByte(code32)
+		e.writeUint32(uint32(len(b)))
+	}
+	e.buf.Write(b)
+}
+
+func (e *encoder) writeSymbols(values symbols) {
+	var elements encoder
+	for _, s := range values {
+		elements.writeUint32(uint32(len(s)))
+		elements.buf.WriteString(string(s))
+	}
+	e.buf.WriteByte(0xf0)
+	// size includes count and element constructor
+	e.writeUint32(uint32(elements.buf.Len() + 5))
+	e.writeUint32(uint32(len(values)))
+	e.buf.WriteByte(0xb3)
+	e.buf.Write(elements.bytes())
+}
+
+func (e *encoder) writeList(items []interface{}) error {
+	if len(items) == 0 {
+		e.buf.WriteByte(0x45)
+		return nil
+	}
+	return e.writeCompound(0xd0, items)
+}
+
+func (e *encoder) writeMap(m map[interface{}]interface{}) error {
+	var items []interface{}
+	for k, v := range m {
+		items = append(items, k, v)
+	}
+	return e.writeCompound(0xd1, items)
+}
+
+func (e *encoder) writeCompound(code uint8, items []interface{}) error {
+	var elements encoder
+	for _, item := range items {
+		if err := elements.writeValue(item); err != nil {
+			return err
+		}
+	}
+	e.buf.WriteByte(code)
+	// size includes the count field
+	e.writeUint32(uint32(elements.buf.Len() + 4))
+	e.writeUint32(uint32(len(items)))
+	e.buf.Write(elements.bytes())
+	return nil
+}
+
+// composite returns a described list for the given descriptor code,
+// removing all trailing null fields
+func composite(code uint64, fields ...interface{}) described {
+	last := len(fields)
+	for last > 0 && fields[last-1] == nil {
+		last--
+	}
+	return described{descriptor: uLong(code), value: fields[:last]}
+}
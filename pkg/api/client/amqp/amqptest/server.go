@@ -0,0 +1,648 @@
+// Package amqptest provides an in-process AMQP 1.0 stand-in server that can be
+// used to unit test AMQP clients without a cluster or a real broker.
+//
+// The server accepts anonymous and SASL PLAIN connections (credentials are not
+// validated), keeps one in-memory queue per address and dispatches queued
+// messages to attached receivers. The outcome returned to senders can be
+// customized through SetOutcome, so clients can be tested against released,
+// rejected or modified deliveries.
+package amqptest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Outcome represents the delivery state used to settle incoming messages
+type Outcome int
+
+const (
+	Accepted Outcome = iota
+	Released
+	Rejected
+	Modified
+)
+
+// OutcomeFunc returns the outcome for the n-th message (starting at 1)
+// sent to the given address
+type OutcomeFunc func(address string, n int) Outcome
+
+const (
+	frameTypeAMQP uint8 = 0x00
+	frameTypeSASL uint8 = 0x01
+
+	// Performatives and other described types
+	descOpen        uint64 = 0x10
+	descBegin       uint64 = 0x11
+	descAttach      uint64 = 0x12
+	descFlow        uint64 = 0x13
+	descTransfer    uint64 = 0x14
+	descDisposition uint64 = 0x15
+	descDetach      uint64 = 0x16
+	descEnd         uint64 = 0x17
+	descClose       uint64 = 0x18
+	descError       uint64 = 0x1d
+	descReceived    uint64 = 0x23
+	descAccepted    uint64 = 0x24
+	descRejected    uint64 = 0x25
+	descReleased    uint64 = 0x26
+	descModified    uint64 = 0x27
+	descSource      uint64 = 0x28
+	descTarget      uint64 = 0x29
+	descSASLMechs   uint64 = 0x40
+	descSASLInit    uint64 = 0x41
+	descSASLOutcome uint64 = 0x44
+
+	maxFrameSize  uint32 = 65536
+	window        uint32 = 1 << 20
+	creditWindow  uint32 = 100
+	frameOverhead uint32 = 128
+)
+
+var (
+	protoAMQP = []byte{'A', 'M', 'Q', 'P', 0, 1, 0, 0}
+	protoSASL = []byte{'A', 'M', 'Q', 'P', 3, 1, 0, 0}
+)
+
+// Server is an in-process AMQP 1.0 stand-in server
+type Server struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	queues   map[string][][]byte
+	received map[string]int
+	links    map[string][]*link
+	conns    map[*connection]struct{}
+	outcome  OutcomeFunc
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a new server listening on a random local port
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener: listener,
+		queues:   map[string][][]byte{},
+		received: map[string]int{},
+		links:    map[string][]*link{},
+		conns:    map[*connection]struct{}{},
+	}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// Addr returns the host:port the server is listening on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Url returns an amqp url that can be used to reach the given address
+func (s *Server) Url(address string) string {
+	return fmt.Sprintf("amqp://%s/%s", s.Addr(), strings.TrimPrefix(address, "/"))
+}
+
+// SetOutcome defines how incoming messages will be settled. Messages are
+// only stored when accepted. By default, all messages are accepted.
+func (s *Server) SetOutcome(fn OutcomeFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.outcome = fn
+}
+
+// QueueDepth returns the number of messages stored for the given address
+func (s *Server) QueueDepth(address string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.queues[address])
+}
+
+// Close stops accepting connections and closes all active connections
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mutex.Lock()
+	for c := range s.conns {
+		_ = c.net.Close()
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		netConn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		c := &connection{
+			server:       s,
+			net:          netConn,
+			sessions:     map[uint16]*session{},
+			peerMaxFrame: maxFrameSize,
+		}
+		s.mutex.Lock()
+		s.conns[c] = struct{}{}
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			_ = c.serve()
+			s.mutex.Lock()
+			c.cleanup()
+			delete(s.conns, c)
+			s.mutex.Unlock()
+			_ = c.net.Close()
+		}()
+	}
+}
+
+// enqueue stores an incoming message (server mutex must be held)
+func (s *Server) enqueue(address string, payload []byte, front bool) {
+	if front {
+		s.queues[address] = append([][]byte{payload}, s.queues[address]...)
+	} else {
+		s.queues[address] = append(s.queues[address], payload)
+	}
+	s.dispatch(address)
+}
+
+// dispatch delivers queued messages to receivers with available credit
+// (server mutex must be held)
+func (s *Server) dispatch(address string) {
+	for progress := true; progress; {
+		progress = false
+		for _, l := range s.links[address] {
+			if l.credit == 0 || len(s.queues[address]) == 0 {
+				continue
+			}
+			payload := s.queues[address][0]
+			s.queues[address] = s.queues[address][1:]
+			if err := l.deliver(payload); err != nil {
+				// connection is broken, message will be requeued on cleanup
+				continue
+			}
+			progress = true
+		}
+	}
+}
+
+type connection struct {
+	server       *Server
+	net          net.Conn
+	writeMutex   sync.Mutex
+	peerMaxFrame uint32
+	sessions     map[uint16]*session
+}
+
+type session struct {
+	conn           *connection
+	channel        uint16
+	nextIncomingID uint32
+	nextOutgoingID uint32
+	links          map[uint32]*link
+}
+
+type link struct {
+	session *session
+	name    string
+	handle  uint32
+	// receiver is true when the client is receiving messages
+	receiver      bool
+	address       string
+	credit        uint32
+	deliveryCount uint32
+	// incoming partial transfer
+	pending        []byte
+	pendingID      uint32
+	pendingSettled bool
+	// outgoing unsettled deliveries
+	unsettled map[uint32][]byte
+	nextTag   uint64
+}
+
+func (c *connection) serve() error {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(c.net, header); err != nil {
+		return err
+	}
+	if string(header) == string(protoSASL) {
+		if err := c.negotiateSASL(); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(c.net, header); err != nil {
+			return err
+		}
+	}
+	if string(header) != string(protoAMQP) {
+		_, _ = c.net.Write(protoAMQP)
+		return fmt.Errorf("unsupported protocol header %v", header)
+	}
+	if _, err := c.net.Write(protoAMQP); err != nil {
+		return err
+	}
+
+	for {
+		frameType, channel, body, err := c.readFrame()
+		if err != nil {
+			return err
+		}
+		// empty frames are heartbeats
+		if len(body) == 0 || frameType != frameTypeAMQP {
+			continue
+		}
+		dec := newDecoder(body)
+		value, err := dec.readValue()
+		if err != nil {
+			return err
+		}
+		performative, ok := value.(described)
+		if !ok {
+			return fmt.Errorf("invalid frame body: %v", value)
+		}
+		c.server.mutex.Lock()
+		closed, err := c.handle(channel, performative, dec.remaining())
+		c.server.mutex.Unlock()
+		if err != nil || closed {
+			return err
+		}
+	}
+}
+
+func (c *connection) negotiateSASL() error {
+	if _, err := c.net.Write(protoSASL); err != nil {
+		return err
+	}
+	mechs := composite(descSASLMechs, symbols{"PLAIN", "ANONYMOUS"})
+	if err := c.writeFrame(frameTypeSASL, 0, mechs, nil); err != nil {
+		return err
+	}
+	// waiting for sasl-init (any credentials are accepted)
+	for {
+		_, _, body, err := c.readFrame()
+		if err != nil {
+			return err
+		}
+		value, err := newDecoder(body).readValue()
+		if err != nil {
+			return err
+		}
+		if d, ok := value.(described); ok && d.code() == descSASLInit {
+			break
+		}
+	}
+	return c.writeFrame(frameTypeSASL, 0, composite(descSASLOutcome, uByte(0)), nil)
+}
+
+func (c *connection) readFrame() (uint8, uint16, []byte, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(c.net, header); err != nil {
+		return 0, 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[0:4])
+	doff := uint32(header[4]) * 4
+	if size < 8 || doff < 8 || doff > size {
+		return 0, 0, nil, fmt.Errorf("invalid frame header %v", header)
+	}
+	data := make([]byte, size-8)
+	if _, err := io.ReadFull(c.net, data); err != nil {
+		return 0, 0, nil, err
+	}
+	return header[5], binary.BigEndian.Uint16(header[6:8]), data[doff-8:], nil
+}
+
+func (c *connection) writeFrame(frameType uint8, channel uint16, performative described, payload []byte) error {
+	var enc encoder
+	if err := enc.writeValue(performative); err != nil {
+		return err
+	}
+	body := append(enc.bytes(), payload...)
+	frame := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(8+len(body)))
+	frame[4] = 2
+	frame[5] = frameType
+	binary.BigEndian.PutUint16(frame[6:8], channel)
+	frame = append(frame, body...)
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	_, err := c.net.Write(frame)
+	return err
+}
+
+func (c *connection) write(channel uint16, performative described, payload []byte) error {
+	return c.writeFrame(frameTypeAMQP, channel, performative, payload)
+}
+
+// handle processes a performative received from the client and returns
+// true when the connection has been closed
+func (c *connection) handle(channel uint16, p described, payload []byte) (bool, error) {
+	switch p.code() {
+	case descOpen:
+		if v, ok := p.field(2).(uint32); ok && v < maxFrameSize {
+			c.peerMaxFrame = v
+		}
+		return false, c.write(0, composite(descOpen, "amqptest", nil, uInt(maxFrameSize), uShort(65535)), nil)
+	case descBegin:
+		s := &session{conn: c, channel: channel, links: map[uint32]*link{}}
+		s.nextIncomingID, _ = p.field(1).(uint32)
+		c.sessions[channel] = s
+		return false, c.write(channel, composite(descBegin, uShort(channel), uInt(0), uInt(window), uInt(window), uInt(65535)), nil)
+	case descEnd:
+		if s, ok := c.sessions[channel]; ok {
+			s.cleanup()
+			delete(c.sessions, channel)
+		}
+		return false, c.write(channel, composite(descEnd), nil)
+	case descClose:
+		_ = c.write(0, composite(descClose), nil)
+		return true, nil
+	}
+
+	s, ok := c.sessions[channel]
+	if !ok {
+		return false, fmt.Errorf("frame received on unknown channel %d", channel)
+	}
+	switch p.code() {
+	case descAttach:
+		return false, s.attach(p)
+	case descFlow:
+		return false, s.flow(p)
+	case descTransfer:
+		return false, s.transfer(p, payload)
+	case descDisposition:
+		return false, s.disposition(p)
+	case descDetach:
+		handle, _ := p.field(0).(uint32)
+		if l, ok := s.links[handle]; ok {
+			l.cleanup()
+			delete(s.links, handle)
+		}
+		closed, _ := p.field(1).(bool)
+		return false, c.write(channel, composite(descDetach, uInt(handle), closed), nil)
+	}
+	return false, nil
+}
+
+// cleanup releases all links from all sessions (server mutex must be held)
+func (c *connection) cleanup() {
+	for _, s := range c.sessions {
+		s.cleanup()
+	}
+	c.sessions = map[uint16]*session{}
+}
+
+func (s *session) cleanup() {
+	for _, l := range s.links {
+		l.cleanup()
+	}
+	s.links = map[uint32]*link{}
+}
+
+func (s *session) attach(p described) error {
+	name, _ := p.field(0).(string)
+	handle, _ := p.field(1).(uint32)
+	role, _ := p.field(2).(bool)
+	l := &link{
+		session:   s,
+		name:      name,
+		handle:    handle,
+		receiver:  role,
+		unsettled: map[uint32][]byte{},
+	}
+
+	// address comes from the source for receivers or from the target for senders
+	terminus := p.field(6)
+	if l.receiver {
+		terminus = p.field(5)
+	}
+	if t, ok := terminus.(described); ok {
+		l.address, _ = t.field(0).(string)
+	}
+	s.links[handle] = l
+
+	source := composite(descSource, l.address)
+	target := composite(descTarget, l.address)
+	if !l.receiver {
+		l.deliveryCount, _ = p.field(9).(uint32)
+		reply := composite(descAttach, name, uInt(handle), true, settleMode(p.field(3)), settleMode(p.field(4)), source, target)
+		if err := s.conn.write(s.channel, reply, nil); err != nil {
+			return err
+		}
+		l.credit = creditWindow
+		return s.writeFlow(l, false)
+	}
+
+	reply := composite(descAttach, name, uInt(handle), false, settleMode(p.field(3)), settleMode(p.field(4)), source, target, nil, nil, uInt(0))
+	if err := s.conn.write(s.channel, reply, nil); err != nil {
+		return err
+	}
+	s.conn.server.links[l.address] = append(s.conn.server.links[l.address], l)
+	return nil
+}
+
+func (s *session) flow(p described) error {
+	if next, ok := p.field(2).(uint32); ok {
+		s.nextIncomingID = next
+	}
+	handle, ok := p.field(4).(uint32)
+	if !ok {
+		return nil
+	}
+	l, ok := s.links[handle]
+	if !ok || !l.receiver {
+		return nil
+	}
+	deliveryCount, ok := p.field(5).(uint32)
+	if !ok {
+		deliveryCount = l.deliveryCount
+	}
+	linkCredit, _ := p.field(6).(uint32)
+	l.credit = deliveryCount + linkCredit - l.deliveryCount
+	s.conn.server.dispatch(l.address)
+
+	// nothing else available, so credit must be consumed when draining
+	drain, _ := p.field(8).(bool)
+	if drain && l.credit > 0 {
+		l.deliveryCount += l.credit
+		l.credit = 0
+		return s.writeFlow(l, drain)
+	}
+	if echo, _ := p.field(9).(bool); echo {
+		return s.writeFlow(l, drain)
+	}
+	return nil
+}
+
+func (s *session) writeFlow(l *link, drain bool) error {
+	flow := composite(descFlow, uInt(s.nextIncomingID), uInt(window), uInt(s.nextOutgoingID), uInt(window),
+		uInt(l.handle), uInt(l.deliveryCount), uInt(l.credit), nil, drain)
+	return s.conn.write(s.channel, flow, nil)
+}
+
+func (s *session) transfer(p described, payload []byte) error {
+	s.nextIncomingID++
+	handle, _ := p.field(0).(uint32)
+	l, ok := s.links[handle]
+	if !ok || l.receiver {
+		return fmt.Errorf("transfer received for unknown handle %d", handle)
+	}
+	if id, ok := p.field(1).(uint32); ok {
+		l.pendingID = id
+		l.pendingSettled, _ = p.field(4).(bool)
+	}
+	l.pending = append(l.pending, payload...)
+	if more, _ := p.field(5).(bool); more {
+		return nil
+	}
+
+	message := l.pending
+	l.pending = nil
+	l.credit--
+	l.deliveryCount++
+
+	server := s.conn.server
+	server.received[l.address]++
+	outcome := Accepted
+	if server.outcome != nil {
+		outcome = server.outcome(l.address, server.received[l.address])
+	}
+	if outcome == Accepted {
+		server.enqueue(l.address, message, false)
+	}
+	if !l.pendingSettled {
+		disposition := composite(descDisposition, true, uInt(l.pendingID), nil, true, deliveryState(outcome))
+		if err := s.conn.write(s.channel, disposition, nil); err != nil {
+			return err
+		}
+	}
+
+	// replenish credit
+	if l.credit < creditWindow/2 {
+		l.credit = creditWindow
+		return s.writeFlow(l, false)
+	}
+	return nil
+}
+
+func (s *session) disposition(p described) error {
+	first, _ := p.field(1).(uint32)
+	last, ok := p.field(2).(uint32)
+	if !ok {
+		last = first
+	}
+	settled, _ := p.field(3).(bool)
+	state, _ := p.field(4).(described)
+
+	for id := first; ; id++ {
+		for _, l := range s.links {
+			payload, ok := l.unsettled[id]
+			if !ok {
+				continue
+			}
+			switch state.code() {
+			case descReleased, descModified:
+				delete(l.unsettled, id)
+				s.conn.server.enqueue(l.address, payload, true)
+			case descReceived:
+				// not yet a terminal state
+			default:
+				delete(l.unsettled, id)
+			}
+		}
+		if id == last {
+			break
+		}
+	}
+
+	if !settled {
+		reply := composite(descDisposition, false, uInt(first), uInt(last), true, state)
+		return s.conn.write(s.channel, reply, nil)
+	}
+	return nil
+}
+
+// deliver sends the given message to the client (server mutex must be held)
+func (l *link) deliver(payload []byte) error {
+	s := l.session
+	deliveryID := s.nextOutgoingID
+	s.nextOutgoingID++
+	l.credit--
+	l.deliveryCount++
+	l.unsettled[deliveryID] = payload
+
+	tag := make([]byte, 8)
+	binary.BigEndian.PutUint64(tag, l.nextTag)
+	l.nextTag++
+
+	chunkSize := int(s.conn.peerMaxFrame - frameOverhead)
+	for first := true; first || len(payload) > 0; first = false {
+		chunk := payload
+		if len(chunk) > chunkSize {
+			chunk = payload[:chunkSize]
+		}
+		payload = payload[len(chunk):]
+		more := len(payload) > 0
+
+		var transfer described
+		if first {
+			transfer = composite(descTransfer, uInt(l.handle), uInt(deliveryID), tag, uInt(0), false, more)
+		} else {
+			transfer = composite(descTransfer, uInt(l.handle), nil, nil, nil, nil, more)
+		}
+		if err := s.conn.write(s.channel, transfer, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanup detaches the link from the server, returning all unsettled
+// messages to their queue (server mutex must be held)
+func (l *link) cleanup() {
+	if !l.receiver {
+		return
+	}
+	server := l.session.conn.server
+	links := server.links[l.address]
+	for i, other := range links {
+		if other == l {
+			server.links[l.address] = append(links[:i], links[i+1:]...)
+			break
+		}
+	}
+	for id, payload := range l.unsettled {
+		delete(l.unsettled, id)
+		server.enqueue(l.address, payload, true)
+	}
+}
+
+// settleMode encodes a settle mode received from the client
+func settleMode(mode interface{}) interface{} {
+	if m, ok := mode.(uint8); ok {
+		return uByte(m)
+	}
+	return nil
+}
+
+// deliveryState returns the described delivery state for the given outcome
+func deliveryState(outcome Outcome) described {
+	switch outcome {
+	case Released:
+		return composite(descReleased)
+	case Rejected:
+		return composite(descRejected, composite(descError, symbol("amqp:precondition-failed"), "rejected by amqptest server"))
+	case Modified:
+		return composite(descModified, true, false)
+	default:
+		return composite(descAccepted)
+	}
+}
@@ -7,7 +7,10 @@ import (
 const (
 	TimeoutDefaultSecs   int = 60
 	TimeoutInterruptSecs int = 60
-	Poll                     = time.Duration(5) * time.Second
+	// Poll was the interval used to poll the status of the clients.
+	//
+	// Deprecated: statuses are now awaited through watches, so it is no longer used.
+	Poll = time.Duration(5) * time.Second
 )
 
 type Client interface {
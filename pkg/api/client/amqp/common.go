@@ -10,6 +10,11 @@ import (
 	"github.com/rh-messaging/shipshape/pkg/framework/log"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
 )
 
 // Common partial implementation for Clients running in Pods/Containers
@@ -53,6 +58,11 @@ func (a *AmqpClientCommon) Status() ClientStatus {
 		return Unknown
 	}
 
+	return podStatus(pod)
+}
+
+// podStatus maps the phase of the given pod to a ClientStatus
+func podStatus(pod *v1.Pod) ClientStatus {
 	switch pod.Status.Phase {
 	case v1.PodPending:
 		return Starting
@@ -100,26 +110,79 @@ func (a *AmqpClientCommon) WaitFor(secs int) ClientStatus {
 
 // WaitForStatus Waits till client status matches one of the given statuses or till it times out
 func (a *AmqpClientCommon) WaitForStatus(secs int, statuses ...ClientStatus) ClientStatus {
-	// Wait timeout
-	timeout := time.Duration(secs) * time.Second
-
-	// Channel to notify when status
-	result := make(chan ClientStatus, 1)
-	go func() {
-		for t := time.Now(); time.Since(t) < timeout; time.Sleep(Poll) {
-			curStatus := a.Status()
-
-			if ClientStatusIn(curStatus, statuses...) {
-				result <- curStatus
-				return
-			}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(secs)*time.Second)
+	defer cancel()
+	return a.WaitForStatusWithContext(ctx, statuses...)
+}
+
+// WaitForStatusWithContext Waits till client status matches one of the given statuses
+// or till the given context is done (in which case Timeout is returned).
+// Status transitions are observed through a watch on the client's Pod, so no
+// polling is done against the API server.
+func (a *AmqpClientCommon) WaitForStatusWithContext(ctx context.Context, statuses ...ClientStatus) ClientStatus {
+	pods := a.Context.Clients.KubeClient.CoreV1().Pods(a.Context.Namespace)
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", a.Pod.Name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return pods.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return pods.Watch(ctx, options)
+		},
+	}
+
+	// user action related conditions take precedence over the pod phase.
+	// Interrupt() holds the lock while the pod is being deleted, so the
+	// Interrupted status is visible once the Deleted event is observed.
+	userStatus := func() (ClientStatus, bool) {
+		a.Mutex.Lock()
+		defer a.Mutex.Unlock()
+		if a.TimedOut {
+			return Timeout, true
+		} else if a.Interrupted {
+			return Interrupted, true
 		}
-	}()
+		return Unknown, false
+	}
 
-	select {
-	case res := <-result:
-		return res
-	case <-time.After(time.Duration(secs) * time.Second):
+	var status ClientStatus
+	matches := func(s ClientStatus) bool {
+		status = s
+		return ClientStatusIn(s, statuses...)
+	}
+
+	precondition := func(store cache.Store) (bool, error) {
+		if s, ok := userStatus(); ok {
+			return matches(s), nil
+		}
+		obj, exists, err := store.GetByKey(a.Context.Namespace + "/" + a.Pod.Name)
+		if err != nil || !exists {
+			return false, err
+		}
+		return matches(podStatus(obj.(*v1.Pod))), nil
+	}
+
+	condition := func(event watch.Event) (bool, error) {
+		pod, ok := event.Object.(*v1.Pod)
+		if !ok || pod.Name != a.Pod.Name {
+			return false, nil
+		}
+		if s, ok := userStatus(); ok {
+			return matches(s), nil
+		}
+		if event.Type == watch.Deleted {
+			return matches(Unknown), nil
+		}
+		return matches(podStatus(pod)), nil
+	}
+
+	if _, err := watchtools.UntilWithSync(ctx, lw, &v1.Pod{}, precondition, condition); err != nil {
+		if ctx.Err() == nil {
+			log.Logf("error waiting for client %s status: %v", a.Name, err)
+		}
 		return Timeout
 	}
+	return status
 }
@@ -0,0 +1,266 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	goamqp "github.com/Azure/go-amqp"
+	"github.com/rh-messaging/shipshape/pkg/api/client/amqp"
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+)
+
+// runFunc implements the sender or receiver specific logic, running
+// against an established AMQP session till it completes or ctx is done
+type runFunc func(ctx context.Context, session *goamqp.Session, address string) error
+
+// portForwardInfo holds the data needed to reach the AMQP endpoint
+// through a port-forward tunnel (when running outside the cluster)
+type portForwardInfo struct {
+	framework *framework.Framework
+	context   *framework.ContextData
+	pod       string
+	port      int
+}
+
+// AmqpNativeClientCommon is a pure-Go implementation of amqp.Client that
+// runs in the test process, talking AMQP 1.0 directly to the given Url.
+// The Url path is used as the AMQP address.
+type AmqpNativeClientCommon struct {
+	Name         string
+	Url          string
+	Timeout      int
+	MessageCount int
+	Mutex        sync.Mutex
+	portForward  *portForwardInfo
+	run          runFunc
+	status       amqp.ClientStatus
+	result       amqp.ResultData
+	err          error
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+// Deploy connects to the AMQP endpoint and starts sending or receiving
+// messages in the background. The client is reported as starting while
+// connecting, which happens without holding the lock, so that it can be
+// queried or interrupted meanwhile.
+func (a *AmqpNativeClientCommon) Deploy() error {
+	dialUrl, address, err := splitUrl(a.Url)
+	if err != nil {
+		return err
+	}
+
+	a.Mutex.Lock()
+	if a.done != nil {
+		a.Mutex.Unlock()
+		return fmt.Errorf("client %s has already been deployed", a.Name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout)*time.Second)
+	a.status = amqp.Starting
+	a.result = amqp.ResultData{Messages: make([]amqp.Message, 0)}
+	a.cancel = cancel
+	a.done = make(chan struct{})
+	a.Mutex.Unlock()
+
+	var forwarder *framework.PortForwarder
+	if a.portForward != nil {
+		pf := a.portForward
+		if forwarder, err = pf.framework.PortForward(pf.context, pf.pod, pf.port); err != nil {
+			a.complete(ctx, err)
+			return err
+		}
+		dialUrl.Host = forwarder.Address()
+	}
+
+	conn, err := goamqp.Dial(ctx, dialUrl.String(), &goamqp.ConnOptions{ContainerID: a.Name})
+	if err != nil {
+		closePortForward(forwarder)
+		err = fmt.Errorf("client %s unable to connect to %s: %v", a.Name, dialUrl.Host, err)
+		a.complete(ctx, err)
+		return err
+	}
+	session, err := conn.NewSession(ctx, nil)
+	if err != nil {
+		_ = conn.Close()
+		closePortForward(forwarder)
+		err = fmt.Errorf("client %s unable to create session: %v", a.Name, err)
+		a.complete(ctx, err)
+		return err
+	}
+
+	go func() {
+		err := a.run(ctx, session, address)
+		_ = conn.Close()
+		closePortForward(forwarder)
+		a.complete(ctx, err)
+	}()
+
+	return nil
+}
+
+// complete records the final status of the client, based on the error
+// returned (if any) and on the state of the given context
+func (a *AmqpNativeClientCommon) complete(ctx context.Context, err error) {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+	a.err = err
+	switch {
+	case a.status == amqp.Interrupted:
+	case err == nil:
+		a.status = amqp.Success
+	case ctx.Err() == context.DeadlineExceeded:
+		a.status = amqp.Timeout
+	default:
+		log.Logf("client %s failed: %v", a.Name, err)
+		a.status = amqp.Error
+	}
+	a.cancel()
+	close(a.done)
+}
+
+func (a *AmqpNativeClientCommon) Status() amqp.ClientStatus {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+	if a.done == nil {
+		return amqp.Unknown
+	}
+	return a.status
+}
+
+func (a *AmqpNativeClientCommon) Running() bool {
+	return amqp.ClientStatusIn(a.Status(), amqp.Starting, amqp.Running)
+}
+
+func (a *AmqpNativeClientCommon) Interrupt() {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+
+	if a.done == nil || !amqp.ClientStatusIn(a.status, amqp.Starting, amqp.Running) {
+		return
+	}
+	a.status = amqp.Interrupted
+	a.cancel()
+}
+
+// Wait Waits for client to complete running (successfully or not), until pre-defined client's timeout.
+func (a *AmqpNativeClientCommon) Wait() amqp.ClientStatus {
+	return a.WaitFor(a.Timeout)
+}
+
+// WaitFor Waits for client to complete running (successfully or not), until given timeout.
+func (a *AmqpNativeClientCommon) WaitFor(secs int) amqp.ClientStatus {
+	a.Mutex.Lock()
+	done := a.done
+	a.Mutex.Unlock()
+	if done == nil {
+		return amqp.Unknown
+	}
+
+	select {
+	case <-done:
+		return a.Status()
+	case <-time.After(time.Duration(secs) * time.Second):
+		return amqp.Timeout
+	}
+}
+
+// Result returns a copy of the results collected so far
+func (a *AmqpNativeClientCommon) Result() amqp.ResultData {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+	result := a.result
+	result.Messages = append([]amqp.Message{}, a.result.Messages...)
+	return result
+}
+
+// Error returns the error that caused the client to fail (if any)
+func (a *AmqpNativeClientCommon) Error() error {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+	return a.err
+}
+
+// setRunning must be called once the link has been attached
+func (a *AmqpNativeClientCommon) setRunning() {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+	if a.status == amqp.Starting {
+		a.status = amqp.Running
+	}
+}
+
+// addMessage records a message that has been sent or received
+func (a *AmqpNativeClientCommon) addMessage(msg amqp.Message, state goamqp.DeliveryState) {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+	a.result.Messages = append(a.result.Messages, msg)
+	a.result.Delivered = len(a.result.Messages)
+	switch state.(type) {
+	case *goamqp.StateAccepted:
+		a.result.Accepted++
+	case *goamqp.StateReleased:
+		a.result.Released++
+	case *goamqp.StateRejected:
+		a.result.Rejected++
+	case *goamqp.StateModified:
+		a.result.Modified++
+	}
+}
+
+// splitUrl returns the url to dial (without the path) and the AMQP address
+func splitUrl(u string) (*url.URL, string, error) {
+	parsedUrl, err := url.Parse(u)
+	if err != nil {
+		return nil, "", err
+	}
+	address := strings.TrimPrefix(parsedUrl.Path, "/")
+	parsedUrl.Path = ""
+	parsedUrl.RawPath = ""
+	return parsedUrl, address, nil
+}
+
+func closePortForward(forwarder *framework.PortForwarder) {
+	if forwarder != nil {
+		forwarder.Close()
+	}
+}
+
+// toMessage converts a go-amqp message into an amqp.Message
+func toMessage(msg *goamqp.Message, address string) amqp.Message {
+	m := amqp.Message{Address: address}
+	if data := msg.GetData(); data != nil {
+		m.Content = string(data)
+	} else if msg.Value != nil {
+		m.Content = fmt.Sprint(msg.Value)
+	}
+	m.ContentSHA1 = contentSHA1(m.Content)
+
+	if h := msg.Header; h != nil {
+		m.Priority = int(h.Priority)
+		m.Ttl = int(h.TTL / time.Millisecond)
+	}
+	if p := msg.Properties; p != nil {
+		if p.To != nil {
+			m.Address = *p.To
+		}
+		if p.MessageID != nil {
+			m.Id = fmt.Sprint(p.MessageID)
+		}
+		if p.CorrelationID != nil {
+			m.CorrelationId = fmt.Sprint(p.CorrelationID)
+		}
+		if p.ReplyTo != nil {
+			m.ReplyTo = *p.ReplyTo
+		}
+		if p.AbsoluteExpiryTime != nil {
+			m.Expiration = int(p.AbsoluteExpiryTime.Unix())
+		}
+		m.UserId = string(p.UserID)
+	}
+	return m
+}
@@ -0,0 +1,38 @@
+// Package native provides a pure-Go AMQP 1.0 implementation of amqp.Client
+// that runs within the test process (instead of running as a Pod), so that
+// clients can be unit tested and do not depend on external images.
+package native
+
+import (
+	"github.com/rh-messaging/shipshape/pkg/framework"
+)
+
+const (
+	Timeout int = 60
+	// Credit is the number of messages a receiver can be given at once
+	Credit int32 = 100
+)
+
+// Common builder properties and methods to be reused by sender/receiver builders
+type AmqpNativeClientBuilderCommon struct {
+	MessageCount int
+	portForward  *portForwardInfo
+}
+
+func (a *AmqpNativeClientBuilderCommon) Messages(count int) *AmqpNativeClientBuilderCommon {
+	a.MessageCount = count
+	return a
+}
+
+// PortForward makes the client connect through a port-forward tunnel
+// to the given port of the provided pod, instead of using the Url host.
+// The tunnel is opened on Deploy() and closed once the client completes.
+func (a *AmqpNativeClientBuilderCommon) PortForward(f *framework.Framework, ctx *framework.ContextData, pod string, port int) *AmqpNativeClientBuilderCommon {
+	a.portForward = &portForwardInfo{
+		framework: f,
+		context:   ctx,
+		pod:       pod,
+		port:      port,
+	}
+	return a
+}
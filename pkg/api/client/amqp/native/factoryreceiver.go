@@ -0,0 +1,75 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	goamqp "github.com/Azure/go-amqp"
+)
+
+type AmqpNativeReceiverBuilder struct {
+	*AmqpNativeClientBuilderCommon
+	receiver *AmqpNativeClientCommon
+}
+
+func NewReceiverBuilder(name string, url string) *AmqpNativeReceiverBuilder {
+	rb := new(AmqpNativeReceiverBuilder)
+	rb.AmqpNativeClientBuilderCommon = &AmqpNativeClientBuilderCommon{}
+	rb.receiver = &AmqpNativeClientCommon{
+		Name:    name,
+		Url:     url,
+		Timeout: Timeout,
+		Mutex:   sync.Mutex{},
+	}
+	return rb
+}
+
+// Count sets the number of messages to receive. When zero (default),
+// the receiver consumes messages until its timeout expires.
+func (a *AmqpNativeReceiverBuilder) Count(count int) *AmqpNativeReceiverBuilder {
+	a.MessageCount = count
+	return a
+}
+
+func (a *AmqpNativeReceiverBuilder) Timeout(timeout int) *AmqpNativeReceiverBuilder {
+	a.receiver.Timeout = timeout
+	return a
+}
+
+func (a *AmqpNativeReceiverBuilder) Build() (*AmqpNativeClientCommon, error) {
+	if a.MessageCount < 0 {
+		return nil, fmt.Errorf("invalid message count: %d", a.MessageCount)
+	}
+	a.receiver.MessageCount = a.MessageCount
+	a.receiver.portForward = a.portForward
+	a.receiver.run = a.receive
+	return a.receiver, nil
+}
+
+// receive accepts incoming messages till MessageCount is reached or, when
+// no count has been specified, till the receiver's timeout expires
+func (a *AmqpNativeReceiverBuilder) receive(ctx context.Context, session *goamqp.Session, address string) error {
+	r := a.receiver
+	receiver, err := session.NewReceiver(ctx, address, &goamqp.ReceiverOptions{Credit: Credit})
+	if err != nil {
+		return fmt.Errorf("unable to attach receiver to %s: %v", address, err)
+	}
+	defer receiver.Close(context.Background())
+	r.setRunning()
+
+	for i := 0; r.MessageCount == 0 || i < r.MessageCount; i++ {
+		msg, err := receiver.Receive(ctx, nil)
+		if err != nil {
+			if r.MessageCount == 0 && ctx.Err() == context.DeadlineExceeded {
+				return nil
+			}
+			return fmt.Errorf("error receiving message %d: %v", i, err)
+		}
+		if err := receiver.AcceptMessage(ctx, msg); err != nil {
+			return fmt.Errorf("error accepting message %d: %v", i, err)
+		}
+		r.addMessage(toMessage(msg, address), &goamqp.StateAccepted{})
+	}
+	return nil
+}
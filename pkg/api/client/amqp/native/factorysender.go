@@ -0,0 +1,88 @@
+package native
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"sync"
+
+	goamqp "github.com/Azure/go-amqp"
+)
+
+type AmqpNativeSenderBuilder struct {
+	*AmqpNativeClientBuilderCommon
+	sender         *AmqpNativeClientCommon
+	MessageContent string
+}
+
+func NewSenderBuilder(name string, url string) *AmqpNativeSenderBuilder {
+	sb := new(AmqpNativeSenderBuilder)
+	sb.AmqpNativeClientBuilderCommon = &AmqpNativeClientBuilderCommon{}
+	sb.sender = &AmqpNativeClientCommon{
+		Name:    name,
+		Url:     url,
+		Timeout: Timeout,
+		Mutex:   sync.Mutex{},
+	}
+	return sb
+}
+
+func (a *AmqpNativeSenderBuilder) Count(count int) *AmqpNativeSenderBuilder {
+	a.MessageCount = count
+	return a
+}
+
+func (a *AmqpNativeSenderBuilder) Timeout(timeout int) *AmqpNativeSenderBuilder {
+	a.sender.Timeout = timeout
+	return a
+}
+
+func (a *AmqpNativeSenderBuilder) Content(content string) *AmqpNativeSenderBuilder {
+	a.MessageContent = content
+	return a
+}
+
+func (a *AmqpNativeSenderBuilder) Build() (*AmqpNativeClientCommon, error) {
+	if a.MessageCount < 0 {
+		return nil, fmt.Errorf("invalid message count: %d", a.MessageCount)
+	}
+	a.sender.MessageCount = a.MessageCount
+	a.sender.portForward = a.portForward
+	a.sender.run = a.send
+	return a.sender, nil
+}
+
+// send delivers MessageCount messages, waiting for the disposition of
+// each one of them before sending the next
+func (a *AmqpNativeSenderBuilder) send(ctx context.Context, session *goamqp.Session, address string) error {
+	s := a.sender
+	sender, err := session.NewSender(ctx, address, nil)
+	if err != nil {
+		return fmt.Errorf("unable to attach sender to %s: %v", address, err)
+	}
+	defer sender.Close(context.Background())
+	s.setRunning()
+
+	for i := 0; i < s.MessageCount; i++ {
+		msg := goamqp.NewMessage([]byte(a.MessageContent))
+		msg.Properties = &goamqp.MessageProperties{
+			MessageID: fmt.Sprintf("%s-%d", s.Name, i),
+			To:        &address,
+		}
+		receipt, err := sender.SendWithReceipt(ctx, msg, nil)
+		if err != nil {
+			return fmt.Errorf("error sending message %d: %v", i, err)
+		}
+		state, err := receipt.Wait(ctx)
+		if err != nil {
+			return fmt.Errorf("error waiting disposition for message %d: %v", i, err)
+		}
+		s.addMessage(toMessage(msg, address), state)
+	}
+	return nil
+}
+
+// contentSHA1 returns the hex encoded SHA1 sum of the given content
+func contentSHA1(content string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(content)))
+}
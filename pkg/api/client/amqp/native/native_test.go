@@ -0,0 +1,149 @@
+package native_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/api/client/amqp"
+	"github.com/rh-messaging/shipshape/pkg/api/client/amqp/amqptest"
+	"github.com/rh-messaging/shipshape/pkg/api/client/amqp/native"
+)
+
+const (
+	testTimeout = 10
+	testAddress = "queue1"
+)
+
+func newServer(t *testing.T) *amqptest.Server {
+	server, err := amqptest.NewServer()
+	if err != nil {
+		t.Fatalf("unable to start amqp server: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+	return server
+}
+
+func deploy(t *testing.T, build func() (*native.AmqpNativeClientCommon, error)) *native.AmqpNativeClientCommon {
+	client, err := build()
+	if err != nil {
+		t.Fatalf("unable to build client: %v", err)
+	}
+	if err := client.Deploy(); err != nil {
+		t.Fatalf("unable to deploy client %s: %v", client.Name, err)
+	}
+	return client
+}
+
+// TestSendReceive validates that messages sent are stored by the server
+// and then consumed by a receiver
+func TestSendReceive(t *testing.T) {
+	server := newServer(t)
+
+	sender := deploy(t, native.NewSenderBuilder("sender", server.Url(testAddress)).
+		Count(5).Content("hello").Timeout(testTimeout).Build)
+	if status := sender.Wait(); status != amqp.Success {
+		t.Fatalf("sender status, got: %v, expected: %v (error: %v)", status, amqp.Success, sender.Error())
+	}
+	result := sender.Result()
+	if result.Delivered != 5 || result.Accepted != 5 {
+		t.Errorf("sender result, got: %+v", result)
+	}
+	if depth := server.QueueDepth(testAddress); depth != 5 {
+		t.Errorf("queue depth, got: %d, expected: %d", depth, 5)
+	}
+
+	receiver := deploy(t, native.NewReceiverBuilder("receiver", server.Url(testAddress)).
+		Count(5).Timeout(testTimeout).Build)
+	if status := receiver.Wait(); status != amqp.Success {
+		t.Fatalf("receiver status, got: %v, expected: %v (error: %v)", status, amqp.Success, receiver.Error())
+	}
+	result = receiver.Result()
+	if result.Delivered != 5 {
+		t.Errorf("receiver delivered, got: %d, expected: %d", result.Delivered, 5)
+	}
+	if result.Messages[0].Content != "hello" || result.Messages[0].ContentSHA1 != sender.Result().Messages[0].ContentSHA1 {
+		t.Errorf("receiver message, got: %+v", result.Messages[0])
+	}
+	if depth := server.QueueDepth(testAddress); depth != 0 {
+		t.Errorf("queue depth, got: %d, expected: %d", depth, 0)
+	}
+}
+
+// TestSenderOutcomes validates that dispositions returned by the server
+// are counted by the sender
+func TestSenderOutcomes(t *testing.T) {
+	server := newServer(t)
+	server.SetOutcome(func(address string, n int) amqptest.Outcome {
+		return amqptest.Outcome(n % 4)
+	})
+
+	sender := deploy(t, native.NewSenderBuilder("sender", server.Url(testAddress)).
+		Count(8).Timeout(testTimeout).Build)
+	if status := sender.Wait(); status != amqp.Success {
+		t.Fatalf("sender status, got: %v, expected: %v (error: %v)", status, amqp.Success, sender.Error())
+	}
+	result := sender.Result()
+	if result.Delivered != 8 || result.Accepted != 2 || result.Released != 2 || result.Rejected != 2 || result.Modified != 2 {
+		t.Errorf("sender result, got: %+v", result)
+	}
+	if depth := server.QueueDepth(testAddress); depth != 2 {
+		t.Errorf("queue depth, got: %d, expected: %d", depth, 2)
+	}
+}
+
+// TestReceiverInterrupt validates that a receiver with no message count
+// keeps running till it is interrupted
+func TestReceiverInterrupt(t *testing.T) {
+	server := newServer(t)
+
+	receiver := deploy(t, native.NewReceiverBuilder("receiver", server.Url(testAddress)).
+		Timeout(testTimeout).Build)
+	if !receiver.Running() {
+		t.Fatalf("receiver expected to be running, status: %v", receiver.Status())
+	}
+	receiver.Interrupt()
+	if status := receiver.Wait(); status != amqp.Interrupted {
+		t.Errorf("receiver status, got: %v, expected: %v", status, amqp.Interrupted)
+	}
+}
+
+// TestInterruptWhileConnecting validates that a client can be queried and
+// interrupted while it is still connecting to an unresponsive endpoint
+func TestInterruptWhileConnecting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer listener.Close()
+
+	client, err := native.NewSenderBuilder("sender", "amqp://"+listener.Addr().String()+"/"+testAddress).
+		Count(1).Timeout(testTimeout).Build()
+	if err != nil {
+		t.Fatalf("unable to build client: %v", err)
+	}
+	deployed := make(chan error)
+	go func() { deployed <- client.Deploy() }()
+
+	// The endpoint accepts the connection but never answers
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept: %v", err)
+	}
+	defer conn.Close()
+	if status := client.Status(); status != amqp.Starting {
+		t.Errorf("status while connecting, got: %v, expected: %v", status, amqp.Starting)
+	}
+	client.Interrupt()
+	select {
+	case err := <-deployed:
+		if err == nil {
+			t.Errorf("expected deploy to fail once interrupted")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("deploy not interrupted")
+	}
+	if status := client.Wait(); status != amqp.Interrupted {
+		t.Errorf("client status, got: %v, expected: %v", status, amqp.Interrupted)
+	}
+}
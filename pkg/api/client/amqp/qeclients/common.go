@@ -2,7 +2,6 @@ package qeclients
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"io"
@@ -32,18 +31,36 @@ func (a *AmqpQEClientCommon) Result() amqp.ResultData {
 	// Close when done reading
 	defer logs.Close()
 
-	// Reading logs into buf
-	buf := new(bytes.Buffer)
-	_, err = io.Copy(buf, logs)
+	// Parsing logged messages and statistics
+	result, err := ParseResult(logs)
 	gomega.Expect(err).To(gomega.BeNil())
 
+	// Locking to set finalResults
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+	if !a.Running() && a.FinalResult == nil {
+		a.FinalResult = &result
+	}
+
+	return result
+}
+
+// ParseResult reads the output produced by the QE clients, where every line
+// is either a json dictionary representing a message (--log-msgs json) or
+// the delivery statistics of the endpoints (--log-stats endpoints), and
+// returns the corresponding ResultData.
+func ParseResult(output io.Reader) (amqp.ResultData, error) {
+	var err error
+
 	// Allows reading line by line
-	reader := bufio.NewReader(buf)
+	reader := bufio.NewReader(output)
 
-	// Unmarshalling message dict
-	var messages []MessageDict
+	// Generating result data
+	result := amqp.ResultData{
+		Messages: make([]amqp.Message, 0),
+	}
 
-	// Iterate through lines\
+	// Iterate through lines
 outer:
 	for {
 		var line, partLine []byte
@@ -57,30 +74,29 @@ outer:
 			if err == io.EOF {
 				break outer
 			}
-			gomega.Expect(err).To(gomega.BeNil())
+			if err != nil {
+				return result, err
+			}
 		}
 
-		var msg MessageDict
-		err = json.Unmarshal([]byte(line), &msg)
-		gomega.Expect(err).To(gomega.BeNil())
-		messages = append(messages, msg)
-	}
-
-	// Generating result data
-	result := amqp.ResultData{
-		Messages:  make([]amqp.Message, 0),
-		Delivered: len(messages),
-	}
-	for _, message := range messages {
-		result.Messages = append(result.Messages, message.ToMessage())
-	}
+		// Statistics are logged as: STATS {...}
+		stats, isStats, err := ParseStats(line)
+		if err != nil {
+			return result, err
+		}
+		if isStats {
+			stats.AddTo(&result)
+			continue
+		}
 
-	// Locking to set finalResults
-	a.Mutex.Lock()
-	defer a.Mutex.Unlock()
-	if !a.Running() && a.FinalResult == nil {
-		a.FinalResult = &result
+		// Unmarshalling message dict
+		var msg MessageDict
+		if err = json.Unmarshal(line, &msg); err != nil {
+			return result, err
+		}
+		result.Messages = append(result.Messages, msg.ToMessage())
 	}
 
-	return result
+	result.Delivered = len(result.Messages)
+	return result, nil
 }
@@ -0,0 +1,51 @@
+package qeclients
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rh-messaging/shipshape/pkg/api/client/amqp"
+)
+
+// TestParseResult validates that messages and delivery statistics logged
+// by each of the QE clients (--log-msgs json --log-stats endpoints) are
+// properly parsed into a ResultData
+func TestParseResult(t *testing.T) {
+	for _, tc := range []struct {
+		client   string
+		expected amqp.ResultData
+	}{
+		{"cli-proton-python", amqp.ResultData{Delivered: 2, Accepted: 2, Rejected: 1}},
+		{"cli-rhea", amqp.ResultData{Delivered: 3, Accepted: 3, Released: 1}},
+		{"cli-qpid-java", amqp.ResultData{Delivered: 1, Accepted: 1, Modified: 2}},
+	} {
+		output, err := os.Open("testdata/" + tc.client + ".log")
+		if err != nil {
+			t.Fatalf("unable to open %s output: %v", tc.client, err)
+		}
+		result, err := ParseResult(output)
+		output.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error parsing result: %v", tc.client, err)
+			continue
+		}
+		if result.Delivered != tc.expected.Delivered || len(result.Messages) != tc.expected.Delivered {
+			t.Errorf("%s: delivered, got: %d, expected: %d", tc.client, result.Delivered, tc.expected.Delivered)
+		}
+		if result.Messages[0].Content != "msg1" || result.Messages[0].Id != "ID:1" {
+			t.Errorf("%s: unexpected message: %+v", tc.client, result.Messages[0])
+		}
+		if result.Accepted != tc.expected.Accepted || result.Released != tc.expected.Released ||
+			result.Rejected != tc.expected.Rejected || result.Modified != tc.expected.Modified {
+			t.Errorf("%s: outcomes, got: %+v", tc.client, result)
+		}
+	}
+
+	// Invalid output
+	for _, output := range []string{"not a json dictionary\n", "STATS {'connection': \n"} {
+		if _, err := ParseResult(strings.NewReader(output)); err == nil {
+			t.Errorf("ParseResult was expected to fail with invalid output: %q", output)
+		}
+	}
+}
@@ -64,6 +64,7 @@ type AmqpQEClientBuilderCommon struct {
 	customImage   string
 	customCommand string
 	MessageCount  int
+	logStats      bool
 }
 
 func (a *AmqpQEClientBuilderCommon) Messages(count int) *AmqpQEClientBuilderCommon {
@@ -71,6 +72,14 @@ func (a *AmqpQEClientBuilderCommon) Messages(count int) *AmqpQEClientBuilderComm
 	return a
 }
 
+// LogStats enables the delivery statistics output, so that the outcome
+// counters (Accepted, Released, Rejected and Modified) from amqp.ResultData
+// get populated
+func (a *AmqpQEClientBuilderCommon) LogStats() *AmqpQEClientBuilderCommon {
+	a.logStats = true
+	return a
+}
+
 func (a *AmqpQEClientBuilderCommon) CustomImage(image string) *AmqpQEClientBuilderCommon {
 	a.customImage = image
 	return a
@@ -74,6 +74,7 @@ func (a *AmqpQEReceiverBuilder) Build() (*AmqpQEClientCommon, error) {
 
 	// Static options
 	cBuilder.AddArgs("--log-msgs", "json")
+	cBuilder.AddArgs(parseLogStats(a.logStats)...)
 
 	// Specific to cli-proton-python and cli-rhea
 	impl := a.receiver.Implementation
@@ -119,6 +119,7 @@ func (a *AmqpQESenderBuilder) Build() (*AmqpQEClientCommon, error) {
 
 	// Static options
 	cBuilder.AddArgs("--log-msgs", "json")
+	cBuilder.AddArgs(parseLogStats(a.logStats)...)
 	if a.customCommand == "" || a.customCommand == "cli-qpid-sender" {
 		cBuilder.AddArgs("--on-release", "retry")
 	}
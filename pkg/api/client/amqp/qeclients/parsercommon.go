@@ -29,3 +29,11 @@ func parseCount(count int) []string {
 func parseTimeout(secs int) []string {
 	return []string{"--timeout", strconv.Itoa(secs)}
 }
+
+// parseLogStats returns the arguments to enable delivery statistics
+func parseLogStats(enabled bool) []string {
+	if !enabled {
+		return []string{}
+	}
+	return []string{"--log-stats", "endpoints"}
+}
@@ -0,0 +1,135 @@
+package qeclients
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rh-messaging/shipshape/pkg/api/client/amqp"
+)
+
+// statsPrefix is the prefix of the lines logged by the QE clients when
+// "--log-stats endpoints" is enabled. It is followed by a dictionary describing
+// the endpoints (connection, sessions and links), formatted as json by cli-rhea
+// and as a python dictionary by cli-proton-python and cli-qpid-java.
+const statsPrefix = "STATS"
+
+// StatsDict represents the delivery statistics logged by the QE clients
+// when "--log-stats endpoints" is enabled. Only the outcome counters are
+// mapped, as they are the only ones exposed through amqp.ResultData.
+type StatsDict struct {
+	Accepted int
+	Released int
+	Rejected int
+	Modified int
+}
+
+// ParseStats parses a line logged by the QE clients, returning false if it is
+// not a statistics line. Outcome counters found on the endpoints are summed up.
+func ParseStats(line []byte) (StatsDict, bool, error) {
+	var stats StatsDict
+
+	line = bytes.TrimSpace(line)
+	if !bytes.HasPrefix(line, []byte(statsPrefix)) {
+		return stats, false, nil
+	}
+	data := bytes.TrimLeft(line[len(statsPrefix):], ": \t")
+
+	var endpoints interface{}
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		if err = json.Unmarshal(pythonToJSON(data), &endpoints); err != nil {
+			return stats, true, fmt.Errorf("invalid statistics %q: %v", data, err)
+		}
+	}
+	stats.collect(endpoints)
+	return stats, true, nil
+}
+
+// collect adds the outcome counters found on the given endpoint statistics.
+// Nested endpoints are only walked when the current one has no counters,
+// so that totals reported by a parent endpoint are not counted twice.
+func (s *StatsDict) collect(endpoint interface{}) {
+	switch e := endpoint.(type) {
+	case []interface{}:
+		for _, child := range e {
+			s.collect(child)
+		}
+	case map[string]interface{}:
+		counters := map[string]*int{
+			"accepted": &s.Accepted,
+			"released": &s.Released,
+			"rejected": &s.Rejected,
+			"modified": &s.Modified,
+		}
+		found := false
+		for key, value := range e {
+			counter, isCounter := counters[strings.ToLower(key)]
+			if count, isNumber := value.(float64); isCounter && isNumber {
+				*counter += int(count)
+				found = true
+			}
+		}
+		if found {
+			return
+		}
+		for _, child := range e {
+			s.collect(child)
+		}
+	}
+}
+
+// pythonLiterals maps the python literals to their json representation
+var pythonLiterals = [][2]string{
+	{"None", "null"},
+	{"True", "true"},
+	{"False", "false"},
+}
+
+// pythonToJSON converts a python dictionary, as printed by the QE clients,
+// into json by replacing single quoted strings and python literals
+func pythonToJSON(data []byte) []byte {
+	var out bytes.Buffer
+	var quote byte
+outer:
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case quote != 0 && c == '\\' && i+1 < len(data):
+			// \' is not a valid json escape sequence
+			if data[i+1] != '\'' {
+				out.WriteByte(c)
+			}
+			out.WriteByte(data[i+1])
+			i++
+		case quote != 0 && c == quote:
+			out.WriteByte('"')
+			quote = 0
+		case quote != 0 && c == '"':
+			out.WriteString(`\"`)
+		case quote != 0:
+			out.WriteByte(c)
+		case c == '\'' || c == '"':
+			out.WriteByte('"')
+			quote = c
+		default:
+			for _, literal := range pythonLiterals {
+				if bytes.HasPrefix(data[i:], []byte(literal[0])) {
+					out.WriteString(literal[1])
+					i += len(literal[0]) - 1
+					continue outer
+				}
+			}
+			out.WriteByte(c)
+		}
+	}
+	return out.Bytes()
+}
+
+// AddTo increments the outcome counters of the given result
+func (s StatsDict) AddTo(result *amqp.ResultData) {
+	result.Accepted += s.Accepted
+	result.Released += s.Released
+	result.Rejected += s.Rejected
+	result.Modified += s.Modified
+}
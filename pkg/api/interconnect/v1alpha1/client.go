@@ -0,0 +1,127 @@
+package v1alpha1
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+var SchemeGroupVersionResource = schema.GroupVersionResource{Group: GroupName, Version: Version, Resource: Resource}
+
+// Interface provides access to the interconnectedcloud.github.io/v1alpha1 resources
+type Interface interface {
+	Interconnects(namespace string) InterconnectInterface
+}
+
+// InterconnectInterface manages the Interconnect resources of a namespace
+type InterconnectInterface interface {
+	Create(ctx context.Context, interconnect *Interconnect, opts metav1.CreateOptions) (*Interconnect, error)
+	Update(ctx context.Context, interconnect *Interconnect, opts metav1.UpdateOptions) (*Interconnect, error)
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*Interconnect, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*InterconnectList, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+type client struct {
+	dynClient dynamic.Interface
+}
+
+// NewForConfig returns a client for the given rest config
+func NewForConfig(restConfig *rest.Config) (Interface, error) {
+	dynClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return New(dynClient), nil
+}
+
+// New returns a client backed by the given dynamic client
+func New(dynClient dynamic.Interface) Interface {
+	return &client{dynClient: dynClient}
+}
+
+func (c *client) Interconnects(namespace string) InterconnectInterface {
+	return &interconnects{resource: c.dynClient.Resource(SchemeGroupVersionResource).Namespace(namespace)}
+}
+
+type interconnects struct {
+	resource dynamic.ResourceInterface
+}
+
+func (i *interconnects) Create(ctx context.Context, interconnect *Interconnect, opts metav1.CreateOptions) (*Interconnect, error) {
+	u, err := ToUnstructured(interconnect)
+	if err != nil {
+		return nil, err
+	}
+	created, err := i.resource.Create(ctx, u, opts)
+	if err != nil {
+		return nil, err
+	}
+	return FromUnstructured(created)
+}
+
+func (i *interconnects) Update(ctx context.Context, interconnect *Interconnect, opts metav1.UpdateOptions) (*Interconnect, error) {
+	u, err := ToUnstructured(interconnect)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := i.resource.Update(ctx, u, opts)
+	if err != nil {
+		return nil, err
+	}
+	return FromUnstructured(updated)
+}
+
+func (i *interconnects) Get(ctx context.Context, name string, opts metav1.GetOptions) (*Interconnect, error) {
+	u, err := i.resource.Get(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return FromUnstructured(u)
+}
+
+func (i *interconnects) List(ctx context.Context, opts metav1.ListOptions) (*InterconnectList, error) {
+	list, err := i.resource.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &InterconnectList{ListMeta: metav1.ListMeta{ResourceVersion: list.GetResourceVersion()}}
+	for n := range list.Items {
+		interconnect, err := FromUnstructured(&list.Items[n])
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, *interconnect)
+	}
+	return result, nil
+}
+
+func (i *interconnects) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return i.resource.Delete(ctx, name, opts)
+}
+
+// ToUnstructured converts the given Interconnect into an unstructured object
+func ToUnstructured(interconnect *Interconnect) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(interconnect)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetAPIVersion(GroupName + "/" + Version)
+	u.SetKind(Kind)
+	return u, nil
+}
+
+// FromUnstructured converts the given unstructured object into an Interconnect
+func FromUnstructured(u *unstructured.Unstructured) (*Interconnect, error) {
+	interconnect := &Interconnect{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, interconnect); err != nil {
+		return nil, err
+	}
+	return interconnect, nil
+}
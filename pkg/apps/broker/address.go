@@ -0,0 +1,110 @@
+package broker
+
+import (
+	"context"
+
+	"github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	RoutingTypeAnycast   = "anycast"
+	RoutingTypeMulticast = "multicast"
+)
+
+// AddressBuilder helps building ActiveMQArtemisAddress resources
+type AddressBuilder struct {
+	address v1beta1.ActiveMQArtemisAddress
+}
+
+// NewAddressBuilder returns a builder for an ActiveMQArtemisAddress with the given
+// resource name, creating the given address (anycast by default) on the brokers
+func NewAddressBuilder(name, address string) *AddressBuilder {
+	b := &AddressBuilder{}
+	b.address.ObjectMeta = metav1.ObjectMeta{Name: name}
+	b.address.Spec.AddressName = address
+	return b.Anycast()
+}
+
+func (b *AddressBuilder) Anycast() *AddressBuilder {
+	return b.RoutingType(RoutingTypeAnycast)
+}
+
+func (b *AddressBuilder) Multicast() *AddressBuilder {
+	return b.RoutingType(RoutingTypeMulticast)
+}
+
+func (b *AddressBuilder) RoutingType(routingType string) *AddressBuilder {
+	b.address.Spec.RoutingType = &routingType
+	return b
+}
+
+// Queue defines the name of the queue to be created for the address
+func (b *AddressBuilder) Queue(queue string) *AddressBuilder {
+	b.address.Spec.QueueName = &queue
+	return b
+}
+
+// QueueConfiguration defines the properties of the queue to be created
+func (b *AddressBuilder) QueueConfiguration(config v1beta1.QueueConfigurationType) *AddressBuilder {
+	b.address.Spec.QueueConfiguration = &config
+	return b
+}
+
+func (b *AddressBuilder) RemoveFromBrokerOnDelete(remove bool) *AddressBuilder {
+	b.address.Spec.RemoveFromBrokerOnDelete = remove
+	return b
+}
+
+// Credentials defines the user and password used by the operator to create the address
+func (b *AddressBuilder) Credentials(user, password string) *AddressBuilder {
+	b.address.Spec.User = &user
+	b.address.Spec.Password = &password
+	return b
+}
+
+// ApplyTo restricts the address to the given ActiveMQArtemis resources
+func (b *AddressBuilder) ApplyTo(crNames ...string) *AddressBuilder {
+	b.address.Spec.ApplyToCrNames = append(b.address.Spec.ApplyToCrNames, crNames...)
+	return b
+}
+
+// Build returns a copy of the ActiveMQArtemisAddress being built
+func (b *AddressBuilder) Build() *v1beta1.ActiveMQArtemisAddress {
+	return b.address.DeepCopy()
+}
+
+func (b *Broker) CreateAddress(address *v1beta1.ActiveMQArtemisAddress) (*v1beta1.ActiveMQArtemisAddress, error) {
+	return b.CreateAddressWithContext(context.TODO(), address)
+}
+
+// CreateAddressWithContext creates the given ActiveMQArtemisAddress in the context's namespace
+func (b *Broker) CreateAddressWithContext(ctx context.Context, address *v1beta1.ActiveMQArtemisAddress) (*v1beta1.ActiveMQArtemisAddress, error) {
+	created := &v1beta1.ActiveMQArtemisAddress{}
+	if err := b.create(ctx, addressKind, address.Name, address, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (b *Broker) GetAddress(name string) (*v1beta1.ActiveMQArtemisAddress, error) {
+	return b.GetAddressWithContext(context.TODO(), name)
+}
+
+// GetAddressWithContext retrieves the ActiveMQArtemisAddress with the given name
+func (b *Broker) GetAddressWithContext(ctx context.Context, name string) (*v1beta1.ActiveMQArtemisAddress, error) {
+	address := &v1beta1.ActiveMQArtemisAddress{}
+	if err := b.get(ctx, addressKind, name, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+func (b *Broker) DeleteAddress(name string) error {
+	return b.DeleteAddressWithContext(context.TODO(), name)
+}
+
+// DeleteAddressWithContext deletes the ActiveMQArtemisAddress with the given name
+func (b *Broker) DeleteAddressWithContext(ctx context.Context, name string) error {
+	return b.delete(ctx, addressKind, name)
+}
@@ -0,0 +1,258 @@
+// Package broker provides helpers to manage ActiveMQArtemis custom resources
+// through the broker operator set up by the framework.
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	GroupName = "broker.amq.io"
+	// DefaultVersion is used when the operator API version does not serve a given kind
+	DefaultVersion = "v1beta1"
+	Kind           = "ActiveMQArtemis"
+	Resource       = "activemqartemises"
+)
+
+var (
+	RetryInterval = 5 * time.Second
+	Timeout       = 5 * time.Minute
+)
+
+// kindInfo describes a custom resource kind managed by the broker operator
+// along with the API versions serving it
+type kindInfo struct {
+	kind     string
+	resource string
+	versions map[string]bool
+}
+
+var (
+	artemisKind   = kindInfo{Kind, Resource, versionSet("v1beta1", "v2alpha1", "v2alpha2", "v2alpha3", "v2alpha4", "v2alpha5")}
+	addressKind   = kindInfo{"ActiveMQArtemisAddress", "activemqartemisaddresses", versionSet("v1beta1", "v2alpha1", "v2alpha2", "v2alpha3")}
+	securityKind  = kindInfo{"ActiveMQArtemisSecurity", "activemqartemissecurities", versionSet("v1alpha1", "v1beta1")}
+	scaledownKind = kindInfo{"ActiveMQArtemisScaledown", "activemqartemisscaledowns", versionSet("v1beta1", "v2alpha1")}
+)
+
+func versionSet(versions ...string) map[string]bool {
+	set := map[string]bool{}
+	for _, version := range versions {
+		set[version] = true
+	}
+	return set
+}
+
+// Artemis is an ActiveMQArtemis custom resource, along with the properties
+// that are not part of the v1beta1 API bundled with shipshape (ignored by
+// operator versions that do not support them)
+type Artemis struct {
+	v1beta1.ActiveMQArtemis
+	Env []corev1.EnvVar
+}
+
+// Broker manages ActiveMQArtemis related resources in the namespace of the
+// given context, using the API versions served by its broker operator
+type Broker struct {
+	ctx *framework.ContextData
+}
+
+func NewBroker(ctx *framework.ContextData) *Broker {
+	return &Broker{ctx: ctx}
+}
+
+func (b *Broker) GetOperator() operators.OperatorSetup {
+	return b.ctx.OperatorMap[operators.OperatorTypeBroker]
+}
+
+// APIVersion returns the ActiveMQArtemis version to be used, based on the
+// API version of the broker operator (or DefaultVersion if not served)
+func (b *Broker) APIVersion() string {
+	return b.apiVersion(artemisKind)
+}
+
+func (b *Broker) apiVersion(k kindInfo) string {
+	if operator := b.GetOperator(); operator != nil && k.versions[operator.APIVersion()] {
+		return operator.APIVersion()
+	}
+	return DefaultVersion
+}
+
+func (b *Broker) resource(k kindInfo) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: GroupName, Version: b.apiVersion(k), Resource: k.resource}
+}
+
+// create converts the given object into the served version of the kind and creates it,
+// storing the created resource into out
+func (b *Broker) create(ctx context.Context, k kindInfo, name string, in, out interface{}) error {
+	u, err := b.toUnstructured(k, in)
+	if err != nil {
+		return err
+	}
+	created, err := b.ctx.CreateResourceGroupVersionWithContext(ctx, b.resource(k), u, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create %s %s: %v", k.kind, name, err)
+	}
+	return fromUnstructured(created, out)
+}
+
+func (b *Broker) update(ctx context.Context, k kindInfo, name string, in, out interface{}) error {
+	u, err := b.toUnstructured(k, in)
+	if err != nil {
+		return err
+	}
+	updated, err := b.ctx.Clients.DynClient.Resource(b.resource(k)).Namespace(b.ctx.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update %s %s: %v", k.kind, name, err)
+	}
+	return fromUnstructured(updated, out)
+}
+
+func (b *Broker) get(ctx context.Context, k kindInfo, name string, obj interface{}) error {
+	u, err := b.ctx.Clients.DynClient.Resource(b.resource(k)).Namespace(b.ctx.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return fromUnstructured(u, obj)
+}
+
+func (b *Broker) delete(ctx context.Context, k kindInfo, name string) error {
+	return b.ctx.Clients.DynClient.Resource(b.resource(k)).Namespace(b.ctx.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+func (b *Broker) Create(artemis *Artemis) (*Artemis, error) {
+	return b.CreateWithContext(context.TODO(), artemis)
+}
+
+// CreateWithContext creates the given ActiveMQArtemis in the context's namespace
+func (b *Broker) CreateWithContext(ctx context.Context, artemis *Artemis) (*Artemis, error) {
+	created := &Artemis{}
+	if err := b.create(ctx, artemisKind, artemis.Name, artemis, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (b *Broker) Update(artemis *Artemis) (*Artemis, error) {
+	return b.UpdateWithContext(context.TODO(), artemis)
+}
+
+// UpdateWithContext updates the given ActiveMQArtemis (its ResourceVersion
+// must match the current one)
+func (b *Broker) UpdateWithContext(ctx context.Context, artemis *Artemis) (*Artemis, error) {
+	updated := &Artemis{}
+	if err := b.update(ctx, artemisKind, artemis.Name, artemis, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (b *Broker) Get(name string) (*Artemis, error) {
+	return b.GetWithContext(context.TODO(), name)
+}
+
+// GetWithContext retrieves the ActiveMQArtemis with the given name
+func (b *Broker) GetWithContext(ctx context.Context, name string) (*Artemis, error) {
+	artemis := &Artemis{}
+	if err := b.get(ctx, artemisKind, name, artemis); err != nil {
+		return nil, err
+	}
+	return artemis, nil
+}
+
+func (b *Broker) Delete(name string) error {
+	return b.DeleteWithContext(context.TODO(), name)
+}
+
+// DeleteWithContext deletes the ActiveMQArtemis with the given name
+func (b *Broker) DeleteWithContext(ctx context.Context, name string) error {
+	return b.delete(ctx, artemisKind, name)
+}
+
+// StatefulSetName returns the name of the StatefulSet created by the
+// operator for the given ActiveMQArtemis
+func StatefulSetName(name string) string {
+	return name + "-ss"
+}
+
+// PodName returns the name of the broker pod with the given ordinal
+func PodName(name string, ordinal int) string {
+	return fmt.Sprintf("%s-%d", StatefulSetName(name), ordinal)
+}
+
+// toUnstructured converts the given object into the API version to be used for its kind
+func (b *Broker) toUnstructured(k kindInfo, obj interface{}) (*unstructured.Unstructured, error) {
+	var env []corev1.EnvVar
+	if artemis, ok := obj.(*Artemis); ok {
+		obj = &artemis.ActiveMQArtemis
+		env = artemis.Env
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetAPIVersion(GroupName + "/" + b.apiVersion(k))
+	u.SetKind(k.kind)
+	u.SetNamespace(b.ctx.Namespace)
+	unstructured.RemoveNestedField(u.Object, "status")
+
+	if len(env) > 0 {
+		envSlice, err := toSlice(env)
+		if err != nil {
+			return nil, err
+		}
+		if err := unstructured.SetNestedSlice(u.Object, envSlice, "spec", "env"); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// fromUnstructured converts the given resource back into obj
+func fromUnstructured(u *unstructured.Unstructured, obj interface{}) error {
+	data, err := u.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	artemis, ok := obj.(*Artemis)
+	if !ok {
+		return json.Unmarshal(data, obj)
+	}
+	artemis.ActiveMQArtemis = v1beta1.ActiveMQArtemis{}
+	if err := json.Unmarshal(data, &artemis.ActiveMQArtemis); err != nil {
+		return err
+	}
+	var env struct {
+		Spec struct {
+			Env []corev1.EnvVar `json:"env"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &env); err != nil {
+		return err
+	}
+	artemis.Env = env.Spec.Env
+	return nil
+}
+
+// toSlice converts the given value into a []interface{} through JSON
+func toSlice(value interface{}) ([]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result []interface{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
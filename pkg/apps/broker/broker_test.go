@@ -0,0 +1,111 @@
+package broker_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rh-messaging/shipshape/pkg/apps/broker"
+	"github.com/rh-messaging/shipshape/pkg/framework"
+)
+
+// newFakeBroker returns a Broker on a framework using fake clients,
+// which is torn down once the test completes
+func newFakeBroker(t *testing.T) (*broker.Broker, *framework.ContextData) {
+	ctx := context.Background()
+	f, err := framework.NewFrameworkBuilder("broker").WithContexts(framework.FakeContext).WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := f.AfterEachWithContext(ctx); err != nil {
+			t.Errorf("unexpected error on teardown: %v", err)
+		}
+	})
+	ctxData := f.GetFirstContext()
+	return broker.NewBroker(ctxData), ctxData
+}
+
+func TestArtemis(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newFakeBroker(t)
+	if b.APIVersion() != broker.DefaultVersion {
+		t.Errorf("api version, got: %s, expected: %s", b.APIVersion(), broker.DefaultVersion)
+	}
+
+	artemis := broker.NewArtemisBuilder("amq").
+		Size(2).
+		Persistence("1Gi", "").
+		AddAcceptor("amqp", 5672, "amqp").
+		AddConnector("connector0", "localhost", 61616).
+		Console(true, "").
+		AddEnv("JAVA_OPTS", "-Xmx512m").
+		Build()
+	if _, err := b.CreateWithContext(ctx, artemis); err != nil {
+		t.Fatalf("unexpected error creating artemis: %v", err)
+	}
+
+	created, err := b.GetWithContext(ctx, "amq")
+	if err != nil {
+		t.Fatalf("unexpected error retrieving artemis: %v", err)
+	}
+	if created.APIVersion != broker.GroupName+"/"+broker.DefaultVersion || created.Kind != broker.Kind {
+		t.Errorf("unexpected type: %s/%s", created.APIVersion, created.Kind)
+	}
+	plan := created.Spec.DeploymentPlan
+	if plan.Size != 2 || !plan.PersistenceEnabled || plan.Storage.Size != "1Gi" {
+		t.Errorf("unexpected deployment plan: %+v", plan)
+	}
+	if len(created.Spec.Acceptors) != 1 || created.Spec.Acceptors[0].Port != 5672 {
+		t.Errorf("unexpected acceptors: %+v", created.Spec.Acceptors)
+	}
+	if len(created.Env) != 1 || created.Env[0].Value != "-Xmx512m" {
+		t.Errorf("unexpected env: %+v", created.Env)
+	}
+
+	created.Spec.DeploymentPlan.Size = 1
+	if _, err := b.UpdateWithContext(ctx, created); err != nil {
+		t.Errorf("unexpected error updating artemis: %v", err)
+	}
+
+	if err := b.DeleteWithContext(ctx, "amq"); err != nil {
+		t.Errorf("unexpected error deleting artemis: %v", err)
+	}
+}
+
+func TestAddressAndSecurity(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newFakeBroker(t)
+
+	address := broker.NewAddressBuilder("orders-address", "orders").Queue("orders").ApplyTo("amq").Build()
+	if _, err := b.CreateAddressWithContext(ctx, address); err != nil {
+		t.Fatalf("unexpected error creating address: %v", err)
+	}
+	createdAddress, err := b.GetAddressWithContext(ctx, "orders-address")
+	if err != nil {
+		t.Fatalf("unexpected error retrieving address: %v", err)
+	}
+	if createdAddress.Spec.AddressName != "orders" || *createdAddress.Spec.RoutingType != broker.RoutingTypeAnycast {
+		t.Errorf("unexpected address: %+v", createdAddress.Spec)
+	}
+
+	security := broker.NewSecurityBuilder("security").
+		AddUser("prop-module", "alice", "secret", "sender").
+		AddUser("prop-module", "bob", "secret", "receiver").
+		BrokerDomain("activemq", "prop-module").
+		AddPermission("orders", broker.OperationSend, "sender").
+		AddPermission("orders", broker.OperationConsume, "receiver").
+		Build()
+	if _, err := b.CreateSecurityWithContext(ctx, security); err != nil {
+		t.Fatalf("unexpected error creating security: %v", err)
+	}
+	createdSecurity, err := b.GetSecurityWithContext(ctx, "security")
+	if err != nil {
+		t.Fatalf("unexpected error retrieving security: %v", err)
+	}
+	if modules := createdSecurity.Spec.LoginModules.PropertiesLoginModules; len(modules) != 1 || len(modules[0].Users) != 2 {
+		t.Errorf("unexpected login modules: %+v", modules)
+	}
+	if settings := createdSecurity.Spec.SecuritySettings.Broker; len(settings) != 1 || len(settings[0].Permissions) != 2 {
+		t.Errorf("unexpected security settings: %+v", settings)
+	}
+}
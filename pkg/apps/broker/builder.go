@@ -0,0 +1,139 @@
+package broker
+
+import (
+	"github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArtemisBuilder helps building ActiveMQArtemis resources
+type ArtemisBuilder struct {
+	artemis Artemis
+}
+
+// NewArtemisBuilder returns a builder for an ActiveMQArtemis with the given name,
+// defaulting to a single broker instance
+func NewArtemisBuilder(name string) *ArtemisBuilder {
+	b := &ArtemisBuilder{}
+	b.artemis.ObjectMeta = metav1.ObjectMeta{Name: name}
+	b.artemis.Spec.DeploymentPlan.Size = 1
+	return b
+}
+
+func (b *ArtemisBuilder) Size(size int32) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.Size = size
+	return b
+}
+
+func (b *ArtemisBuilder) Image(image string) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.Image = image
+	return b
+}
+
+func (b *ArtemisBuilder) InitImage(image string) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.InitImage = image
+	return b
+}
+
+func (b *ArtemisBuilder) Labels(labels map[string]string) *ArtemisBuilder {
+	b.artemis.Labels = labels
+	return b
+}
+
+// Persistence enables persistence, using the given storage size and class (if not empty)
+func (b *ArtemisBuilder) Persistence(size, storageClassName string) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.PersistenceEnabled = true
+	b.artemis.Spec.DeploymentPlan.Storage = v1beta1.StorageType{
+		Size:             size,
+		StorageClassName: storageClassName,
+	}
+	return b
+}
+
+func (b *ArtemisBuilder) JournalType(journalType string) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.JournalType = journalType
+	return b
+}
+
+func (b *ArtemisBuilder) MessageMigration(enabled bool) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.MessageMigration = &enabled
+	return b
+}
+
+func (b *ArtemisBuilder) Clustered(clustered bool) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.Clustered = &clustered
+	return b
+}
+
+func (b *ArtemisBuilder) RequireLogin(requireLogin bool) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.RequireLogin = requireLogin
+	return b
+}
+
+func (b *ArtemisBuilder) AdminCredentials(user, password string) *ArtemisBuilder {
+	b.artemis.Spec.AdminUser = user
+	b.artemis.Spec.AdminPassword = password
+	return b
+}
+
+func (b *ArtemisBuilder) JolokiaAgent(enabled bool) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.JolokiaAgentEnabled = enabled
+	return b
+}
+
+func (b *ArtemisBuilder) Resources(resources corev1.ResourceRequirements) *ArtemisBuilder {
+	b.artemis.Spec.DeploymentPlan.Resources = resources
+	return b
+}
+
+// AddAcceptor adds an acceptor with the given name, port and (comma separated) protocols
+func (b *ArtemisBuilder) AddAcceptor(name string, port int32, protocols string) *ArtemisBuilder {
+	return b.WithAcceptors(v1beta1.AcceptorType{Name: name, Port: port, Protocols: protocols})
+}
+
+func (b *ArtemisBuilder) WithAcceptors(acceptors ...v1beta1.AcceptorType) *ArtemisBuilder {
+	b.artemis.Spec.Acceptors = append(b.artemis.Spec.Acceptors, acceptors...)
+	return b
+}
+
+// AddConnector adds a connector with the given name, host and port
+func (b *ArtemisBuilder) AddConnector(name, host string, port int32) *ArtemisBuilder {
+	return b.WithConnectors(v1beta1.ConnectorType{Name: name, Host: host, Port: port})
+}
+
+func (b *ArtemisBuilder) WithConnectors(connectors ...v1beta1.ConnectorType) *ArtemisBuilder {
+	b.artemis.Spec.Connectors = append(b.artemis.Spec.Connectors, connectors...)
+	return b
+}
+
+// Console exposes the management console (with SSL if sslSecret is not empty)
+func (b *ArtemisBuilder) Console(expose bool, sslSecret string) *ArtemisBuilder {
+	b.artemis.Spec.Console = v1beta1.ConsoleType{
+		Expose:     expose,
+		SSLEnabled: sslSecret != "",
+		SSLSecret:  sslSecret,
+	}
+	return b
+}
+
+func (b *ArtemisBuilder) BrokerProperties(properties ...string) *ArtemisBuilder {
+	b.artemis.Spec.BrokerProperties = append(b.artemis.Spec.BrokerProperties, properties...)
+	return b
+}
+
+// AddEnv adds an environment variable to the broker containers
+func (b *ArtemisBuilder) AddEnv(name, value string) *ArtemisBuilder {
+	return b.WithEnv(corev1.EnvVar{Name: name, Value: value})
+}
+
+func (b *ArtemisBuilder) WithEnv(env ...corev1.EnvVar) *ArtemisBuilder {
+	b.artemis.Env = append(b.artemis.Env, env...)
+	return b
+}
+
+// Build returns a copy of the ActiveMQArtemis being built
+func (b *ArtemisBuilder) Build() *Artemis {
+	artemis := &Artemis{Env: append([]corev1.EnvVar{}, b.artemis.Env...)}
+	b.artemis.ActiveMQArtemis.DeepCopyInto(&artemis.ActiveMQArtemis)
+	return artemis
+}
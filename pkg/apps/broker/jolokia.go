@@ -0,0 +1,464 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/framework"
+)
+
+const (
+	// ConsolePort is the port of the management console (serving jolokia) on broker pods
+	ConsolePort = 8161
+	// JolokiaPath is the path of the jolokia endpoint on the management console
+	JolokiaPath = "/console/jolokia"
+	// jolokiaDomain is the JMX domain of the Artemis MBeans
+	jolokiaDomain = "org.apache.activemq.artemis"
+)
+
+var (
+	// JolokiaTimeout is the maximum amount of time to wait for a jolokia request
+	JolokiaTimeout = 30 * time.Second
+)
+
+// jolokiaTransport sends a jolokia request, returning the raw response body
+type jolokiaTransport interface {
+	post(ctx context.Context, body []byte) ([]byte, error)
+}
+
+// JolokiaClient invokes the management operations of an Artemis broker through its
+// jolokia endpoint. Clients created for broker pods must be closed when no longer needed.
+type JolokiaClient struct {
+	transport  jolokiaTransport
+	brokerName string
+	forwarder  *framework.PortForwarder
+}
+
+// Address holds the management attributes of an address
+type Address struct {
+	Name         string
+	RoutingTypes []string
+	QueueNames   []string
+}
+
+// Queue holds the management attributes of a queue
+type Queue struct {
+	Name                 string
+	Address              string
+	RoutingType          string
+	Durable              bool
+	Paused               bool
+	MessageCount         int64
+	ConsumerCount        int64
+	DeliveringCount      int64
+	MessagesAdded        int64
+	MessagesAcknowledged int64
+	MessagesExpired      int64
+	MessagesKilled       int64
+
+	mbean string
+}
+
+// Consumer holds the information reported by the broker about a queue consumer
+type Consumer struct {
+	ConsumerID      int64  `json:"consumerID"`
+	ConnectionID    string `json:"connectionID"`
+	SessionID       string `json:"sessionID"`
+	QueueName       string `json:"queueName"`
+	BrowseOnly      bool   `json:"browseOnly"`
+	CreationTime    int64  `json:"creationTime"`
+	DeliveringCount int64  `json:"deliveringCount"`
+}
+
+// NewJolokiaClient returns a client for the jolokia endpoint at the given URL
+// (like http://localhost:8161/console/jolokia), authenticating with the given credentials
+func NewJolokiaClient(url, user, password string) *JolokiaClient {
+	return &JolokiaClient{
+		transport: &httpTransport{
+			url:      url,
+			user:     user,
+			password: password,
+			client:   &http.Client{Timeout: JolokiaTimeout},
+		},
+	}
+}
+
+func (b *Broker) JolokiaClient(name string, ordinal int) (*JolokiaClient, error) {
+	return b.JolokiaClientWithContext(context.TODO(), name, ordinal)
+}
+
+// JolokiaClientWithContext returns a client for the jolokia endpoint of the broker pod with the
+// given ordinal, reached through a port-forward to its console port and authenticated with the
+// admin credentials of the given ActiveMQArtemis. The client must be closed when no longer needed.
+func (b *Broker) JolokiaClientWithContext(ctx context.Context, name string, ordinal int) (*JolokiaClient, error) {
+	user, password, err := b.CredentialsWithContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	forwarder, err := b.ctx.PortForward(PodName(name, ordinal), ConsolePort)
+	if err != nil {
+		return nil, err
+	}
+	client := NewJolokiaClient("http://"+forwarder.Address()+JolokiaPath, user, password)
+	client.forwarder = forwarder
+	return client, nil
+}
+
+func (b *Broker) JolokiaExecClient(name string, ordinal int) (*JolokiaClient, error) {
+	return b.JolokiaExecClientWithContext(context.TODO(), name, ordinal)
+}
+
+// JolokiaExecClientWithContext returns a client for the jolokia endpoint of the broker pod with
+// the given ordinal, sending requests with curl from inside the pod. It can be used when ports
+// cannot be forwarded from the machine running the tests.
+func (b *Broker) JolokiaExecClientWithContext(ctx context.Context, name string, ordinal int) (*JolokiaClient, error) {
+	user, password, err := b.CredentialsWithContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	pod := PodName(name, ordinal)
+	return &JolokiaClient{
+		transport: &execTransport{
+			ctxData:  b.ctx,
+			pod:      pod,
+			url:      fmt.Sprintf("http://%s:%d%s", pod, ConsolePort, JolokiaPath),
+			user:     user,
+			password: password,
+		},
+	}, nil
+}
+
+// Close stops the port-forward used by the client (if any)
+func (c *JolokiaClient) Close() {
+	if c.forwarder != nil {
+		c.forwarder.Close()
+	}
+}
+
+type jolokiaRequest struct {
+	Type      string        `json:"type"`
+	MBean     string        `json:"mbean"`
+	Attribute interface{}   `json:"attribute,omitempty"`
+	Operation string        `json:"operation,omitempty"`
+	Arguments []interface{} `json:"arguments,omitempty"`
+}
+
+type jolokiaResponse struct {
+	Status    int             `json:"status"`
+	Value     json.RawMessage `json:"value"`
+	Error     string          `json:"error"`
+	ErrorType string          `json:"error_type"`
+}
+
+// request sends the given request, unmarshalling the returned value into value (if not nil)
+func (c *JolokiaClient) request(ctx context.Context, request jolokiaRequest, value interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	data, err := c.transport.post(ctx, body)
+	if err != nil {
+		return fmt.Errorf("jolokia %s request on %s failed: %v", request.Type, request.MBean, err)
+	}
+	var response jolokiaResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("invalid jolokia response: %v: %s", err, string(data))
+	}
+	if response.Status != http.StatusOK {
+		return fmt.Errorf("jolokia %s request on %s failed with status %d: %s", request.Type, request.MBean, response.Status, response.Error)
+	}
+	if value == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Value, value)
+}
+
+func (c *JolokiaClient) BrokerName() (string, error) {
+	return c.BrokerNameWithContext(context.TODO())
+}
+
+// BrokerNameWithContext returns the name of the broker, as registered in its MBeans
+func (c *JolokiaClient) BrokerNameWithContext(ctx context.Context) (string, error) {
+	if c.brokerName != "" {
+		return c.brokerName, nil
+	}
+	var mbeans []string
+	if err := c.request(ctx, jolokiaRequest{Type: "search", MBean: jolokiaDomain + ":broker=*"}, &mbeans); err != nil {
+		return "", err
+	}
+	if len(mbeans) == 0 {
+		return "", fmt.Errorf("no broker MBean found")
+	}
+	for _, property := range strings.Split(strings.TrimPrefix(mbeans[0], jolokiaDomain+":"), ",") {
+		if strings.HasPrefix(property, "broker=") {
+			c.brokerName = strings.Trim(strings.TrimPrefix(property, "broker="), `"`)
+		}
+	}
+	return c.brokerName, nil
+}
+
+func (c *JolokiaClient) brokerMBean(ctx context.Context) (string, error) {
+	brokerName, err := c.BrokerNameWithContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`%s:broker="%s"`, jolokiaDomain, brokerName), nil
+}
+
+func (c *JolokiaClient) AddressNames() ([]string, error) {
+	return c.AddressNamesWithContext(context.TODO())
+}
+
+// AddressNamesWithContext returns the names of all addresses defined on the broker
+func (c *JolokiaClient) AddressNamesWithContext(ctx context.Context) ([]string, error) {
+	return c.readBrokerNames(ctx, "AddressNames")
+}
+
+func (c *JolokiaClient) QueueNames() ([]string, error) {
+	return c.QueueNamesWithContext(context.TODO())
+}
+
+// QueueNamesWithContext returns the names of all queues defined on the broker
+func (c *JolokiaClient) QueueNamesWithContext(ctx context.Context) ([]string, error) {
+	return c.readBrokerNames(ctx, "QueueNames")
+}
+
+func (c *JolokiaClient) readBrokerNames(ctx context.Context, attribute string) ([]string, error) {
+	mbean, err := c.brokerMBean(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := c.request(ctx, jolokiaRequest{Type: "read", MBean: mbean, Attribute: attribute}, &names); err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *JolokiaClient) Addresses() ([]Address, error) {
+	return c.AddressesWithContext(context.TODO())
+}
+
+// AddressesWithContext returns the addresses defined on the broker, sorted by name
+func (c *JolokiaClient) AddressesWithContext(ctx context.Context) ([]Address, error) {
+	mbean, err := c.brokerMBean(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]struct {
+		Address      string
+		RoutingTypes []string
+		QueueNames   []string
+	}
+	pattern := mbean + ",component=addresses,address=*"
+	request := jolokiaRequest{Type: "read", MBean: pattern, Attribute: []string{"Address", "RoutingTypes", "QueueNames"}}
+	if err := c.request(ctx, request, &values); err != nil {
+		return nil, err
+	}
+	var addresses []Address
+	for _, value := range values {
+		addresses = append(addresses, Address{Name: value.Address, RoutingTypes: value.RoutingTypes, QueueNames: value.QueueNames})
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Name < addresses[j].Name
+	})
+	return addresses, nil
+}
+
+func (c *JolokiaClient) Queues() ([]Queue, error) {
+	return c.QueuesWithContext(context.TODO())
+}
+
+// QueuesWithContext returns the queues defined on the broker, sorted by name
+func (c *JolokiaClient) QueuesWithContext(ctx context.Context) ([]Queue, error) {
+	mbean, err := c.brokerMBean(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]Queue
+	pattern := mbean + ",component=addresses,address=*,subcomponent=queues,routing-type=*,queue=*"
+	attributes := []string{"Name", "Address", "RoutingType", "Durable", "Paused", "MessageCount", "ConsumerCount",
+		"DeliveringCount", "MessagesAdded", "MessagesAcknowledged", "MessagesExpired", "MessagesKilled"}
+	if err := c.request(ctx, jolokiaRequest{Type: "read", MBean: pattern, Attribute: attributes}, &values); err != nil {
+		return nil, err
+	}
+	var queues []Queue
+	for mbean, queue := range values {
+		queue.mbean = mbean
+		queue.RoutingType = strings.ToLower(queue.RoutingType)
+		queues = append(queues, queue)
+	}
+	sort.Slice(queues, func(i, j int) bool {
+		return queues[i].Name < queues[j].Name
+	})
+	return queues, nil
+}
+
+func (c *JolokiaClient) Queue(name string) (*Queue, error) {
+	return c.QueueWithContext(context.TODO(), name)
+}
+
+// QueueWithContext returns the queue with the given name
+func (c *JolokiaClient) QueueWithContext(ctx context.Context, name string) (*Queue, error) {
+	queues, err := c.QueuesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, queue := range queues {
+		if queue.Name == name {
+			return &queue, nil
+		}
+	}
+	return nil, fmt.Errorf("queue %s not found", name)
+}
+
+func (c *JolokiaClient) MessageCount(queue string) (int64, error) {
+	return c.MessageCountWithContext(context.TODO(), queue)
+}
+
+// MessageCountWithContext returns the number of messages currently in the given queue
+func (c *JolokiaClient) MessageCountWithContext(ctx context.Context, queue string) (int64, error) {
+	q, err := c.QueueWithContext(ctx, queue)
+	if err != nil {
+		return 0, err
+	}
+	return q.MessageCount, nil
+}
+
+func (c *JolokiaClient) ConsumerCount(queue string) (int64, error) {
+	return c.ConsumerCountWithContext(context.TODO(), queue)
+}
+
+// ConsumerCountWithContext returns the number of consumers attached to the given queue
+func (c *JolokiaClient) ConsumerCountWithContext(ctx context.Context, queue string) (int64, error) {
+	q, err := c.QueueWithContext(ctx, queue)
+	if err != nil {
+		return 0, err
+	}
+	return q.ConsumerCount, nil
+}
+
+func (c *JolokiaClient) Consumers(queue string) ([]Consumer, error) {
+	return c.ConsumersWithContext(context.TODO(), queue)
+}
+
+// ConsumersWithContext returns the consumers attached to the given queue
+func (c *JolokiaClient) ConsumersWithContext(ctx context.Context, queue string) ([]Consumer, error) {
+	q, err := c.QueueWithContext(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+	var result string
+	if err := c.request(ctx, jolokiaRequest{Type: "exec", MBean: q.mbean, Operation: "listConsumersAsJSON()"}, &result); err != nil {
+		return nil, err
+	}
+	var consumers []Consumer
+	if err := json.Unmarshal([]byte(result), &consumers); err != nil {
+		return nil, fmt.Errorf("unable to parse consumers of queue %s: %v", queue, err)
+	}
+	return consumers, nil
+}
+
+func (c *JolokiaClient) PurgeQueue(queue string) (int64, error) {
+	return c.PurgeQueueWithContext(context.TODO(), queue)
+}
+
+// PurgeQueueWithContext removes all messages from the given queue, returning the number of removed messages
+func (c *JolokiaClient) PurgeQueueWithContext(ctx context.Context, queue string) (int64, error) {
+	q, err := c.QueueWithContext(ctx, queue)
+	if err != nil {
+		return 0, err
+	}
+	var removed int64
+	if err := c.request(ctx, jolokiaRequest{Type: "exec", MBean: q.mbean, Operation: "removeAllMessages()"}, &removed); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+func (c *JolokiaClient) AddressSettings(address string) (map[string]interface{}, error) {
+	return c.AddressSettingsWithContext(context.TODO(), address)
+}
+
+// AddressSettingsWithContext returns the address settings applied to the given address
+func (c *JolokiaClient) AddressSettingsWithContext(ctx context.Context, address string) (map[string]interface{}, error) {
+	mbean, err := c.brokerMBean(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var result string
+	request := jolokiaRequest{Type: "exec", MBean: mbean, Operation: "getAddressSettingsAsJSON(java.lang.String)", Arguments: []interface{}{address}}
+	if err := c.request(ctx, request, &result); err != nil {
+		return nil, err
+	}
+	settings := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(result), &settings); err != nil {
+		return nil, fmt.Errorf("unable to parse address settings of %s: %v", address, err)
+	}
+	return settings, nil
+}
+
+// httpTransport posts jolokia requests to a reachable endpoint
+type httpTransport struct {
+	url      string
+	user     string
+	password string
+	client   *http.Client
+}
+
+func (t *httpTransport) post(ctx context.Context, body []byte) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.SetBasicAuth(t.user, t.password)
+	request.Header.Set("Content-Type", "application/json")
+	// The console only accepts jolokia requests with an allowed origin
+	request.Header.Set("Origin", "http://localhost")
+	response, err := t.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http status %s: %s", response.Status, string(data))
+	}
+	return data, nil
+}
+
+// execTransport posts jolokia requests using curl from inside a broker pod
+type execTransport struct {
+	ctxData  *framework.ContextData
+	pod      string
+	url      string
+	user     string
+	password string
+}
+
+func (t *execTransport) post(ctx context.Context, body []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, JolokiaTimeout)
+	defer cancel()
+	args := []string{"-s", "-f",
+		"-u", t.user + ":" + t.password,
+		"-H", "Content-Type: application/json",
+		"-H", "Origin: http://localhost",
+		"-d", string(body),
+		t.url}
+	stdout, stderr, err := t.ctxData.ExecuteWithContext(ctx, "curl", args, t.pod)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, stdout+stderr)
+	}
+	return []byte(stdout), nil
+}
@@ -0,0 +1,77 @@
+package broker_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rh-messaging/shipshape/pkg/apps/broker"
+)
+
+func TestJolokiaClient(t *testing.T) {
+	const queueMBean = `org.apache.activemq.artemis:broker="amq-broker",component=addresses,address="orders",subcomponent=queues,routing-type="anycast",queue="orders"`
+	var purged string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "admin" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var request map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		var value interface{}
+		switch request["type"] {
+		case "search":
+			value = []string{`org.apache.activemq.artemis:broker="amq-broker"`}
+		case "read":
+			value = map[string]interface{}{
+				queueMBean: map[string]interface{}{
+					"Name": "orders", "Address": "orders", "RoutingType": "ANYCAST", "Durable": true,
+					"MessageCount": 10, "ConsumerCount": 2, "MessagesAdded": 12,
+				},
+			}
+		case "exec":
+			purged = request["mbean"].(string)
+			value = 10
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": 200, "value": value})
+	}))
+	defer server.Close()
+
+	client := broker.NewJolokiaClient(server.URL+broker.JolokiaPath, "admin", "admin")
+	defer client.Close()
+
+	name, err := client.BrokerName()
+	if err != nil || name != "amq-broker" {
+		t.Errorf("unexpected broker name: %s (%v)", name, err)
+	}
+	queue, err := client.Queue("orders")
+	if err != nil {
+		t.Fatalf("unexpected error retrieving queue: %v", err)
+	}
+	if queue.MessageCount != 10 || queue.ConsumerCount != 2 || queue.RoutingType != broker.RoutingTypeAnycast || !queue.Durable {
+		t.Errorf("unexpected queue: %+v", queue)
+	}
+	if _, err := client.Queue("missing"); err == nil {
+		t.Errorf("expected error retrieving missing queue")
+	}
+	removed, err := client.PurgeQueue("orders")
+	if err != nil || removed != 10 || purged != queueMBean {
+		t.Errorf("unexpected purge result: %d on %s (%v)", removed, purged, err)
+	}
+
+	unauthorized := broker.NewJolokiaClient(server.URL+broker.JolokiaPath, "admin", "wrong")
+	if _, err := unauthorized.QueueNames(); err == nil {
+		t.Errorf("expected error with invalid credentials")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.QueueNamesWithContext(ctx); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("expected cancelled request, got: %v", err)
+	}
+}
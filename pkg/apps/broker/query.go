@@ -0,0 +1,225 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// ArtemisCLI is the path of the artemis command inside the broker containers
+	ArtemisCLI = "/home/jboss/amq-broker/bin/artemis"
+	// CorePort is the port of the default acceptor created by the operator
+	CorePort = 61616
+)
+
+// QueueStat holds the statistics reported by the broker for a queue
+type QueueStat struct {
+	Name          string
+	Address       string
+	RoutingType   string
+	ConsumerCount int
+	MessageCount  int
+	MessagesAdded int
+	MessagesAcked int
+}
+
+func (b *Broker) Credentials(name string) (string, string, error) {
+	return b.CredentialsWithContext(context.TODO(), name)
+}
+
+// CredentialsWithContext returns the admin user and password of the given ActiveMQArtemis,
+// read from its spec or from the credentials secret generated by the operator
+func (b *Broker) CredentialsWithContext(ctx context.Context, name string) (string, string, error) {
+	artemis, err := b.GetWithContext(ctx, name)
+	if err != nil {
+		return "", "", err
+	}
+	if artemis.Spec.AdminUser != "" && artemis.Spec.AdminPassword != "" {
+		return artemis.Spec.AdminUser, artemis.Spec.AdminPassword, nil
+	}
+	secretName := name + "-credentials-secret"
+	secret, err := b.ctx.Clients.KubeClient.CoreV1().Secrets(b.ctx.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve credentials for %s %s: %v", Kind, name, err)
+	}
+	return string(secret.Data["AMQ_USER"]), string(secret.Data["AMQ_PASSWORD"]), nil
+}
+
+// artemis runs the artemis CLI on the broker pod with the given ordinal, connecting to the
+// local acceptor with the given credentials. An error is returned if the command fails,
+// including when the broker rejects the credentials.
+func (b *Broker) artemis(ctx context.Context, name string, ordinal int, user, password string, args ...string) (string, error) {
+	pod := PodName(name, ordinal)
+	args = append(args,
+		"--url", fmt.Sprintf("tcp://%s:%d", pod, CorePort),
+		"--user", user,
+		"--password", password)
+	stdout, stderr, err := b.ctx.ExecuteWithContext(ctx, ArtemisCLI, args, pod)
+	output := stdout + stderr
+	if err != nil {
+		return output, fmt.Errorf("artemis %s failed on %s: %v: %s", args[0], pod, err, output)
+	}
+	// The CLI reports some errors (like security exceptions) without a failing exit code
+	if strings.Contains(output, "AMQ119031") || strings.Contains(output, "AMQ229031") || strings.Contains(output, "ActiveMQSecurityException") {
+		return output, fmt.Errorf("artemis %s rejected on %s: %s", args[0], pod, output)
+	}
+	return output, nil
+}
+
+func (b *Broker) QueueStats(name string, ordinal int) ([]QueueStat, error) {
+	return b.QueueStatsWithContext(context.TODO(), name, ordinal)
+}
+
+// QueueStatsWithContext returns the statistics of the queues on the broker pod with the given ordinal
+func (b *Broker) QueueStatsWithContext(ctx context.Context, name string, ordinal int) ([]QueueStat, error) {
+	user, password, err := b.CredentialsWithContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	output, err := b.artemis(ctx, name, ordinal, user, password, "queue", "stat", "--maxRows", "1000")
+	if err != nil {
+		return nil, err
+	}
+	return ParseQueueStats(output)
+}
+
+func (b *Broker) GetQueue(name string, ordinal int, queue string) (*QueueStat, error) {
+	return b.GetQueueWithContext(context.TODO(), name, ordinal, queue)
+}
+
+// GetQueueWithContext returns the statistics of the given queue, or nil if it does not exist
+func (b *Broker) GetQueueWithContext(ctx context.Context, name string, ordinal int, queue string) (*QueueStat, error) {
+	stats, err := b.QueueStatsWithContext(ctx, name, ordinal)
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range stats {
+		if stat.Name == queue {
+			return &stat, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *Broker) WaitForQueue(name string, ordinal int, queue string, timeout time.Duration) (*QueueStat, error) {
+	return b.WaitForQueueWithContext(context.TODO(), name, ordinal, queue, timeout)
+}
+
+// WaitForQueueWithContext waits for the given queue to be defined on the broker pod with the given ordinal
+func (b *Broker) WaitForQueueWithContext(ctx context.Context, name string, ordinal int, queue string, timeout time.Duration) (*QueueStat, error) {
+	var stat *QueueStat
+	err := wait.PollImmediateWithContext(ctx, RetryInterval, timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		if stat, err = b.GetQueueWithContext(ctx, name, ordinal, queue); err != nil {
+			log.Logf("Unable to query queues on %s: %v", PodName(name, ordinal), err)
+			return false, nil
+		}
+		return stat != nil, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("queue %s not found on %s: %v", queue, PodName(name, ordinal), err)
+	}
+	return stat, nil
+}
+
+func (b *Broker) WaitForMessageCount(name string, ordinal int, queue string, count int, timeout time.Duration) error {
+	return b.WaitForMessageCountWithContext(context.TODO(), name, ordinal, queue, count, timeout)
+}
+
+// WaitForMessageCountWithContext waits for the given queue to hold the expected number of messages
+// on the broker pod with the given ordinal (useful to verify messages have been migrated)
+func (b *Broker) WaitForMessageCountWithContext(ctx context.Context, name string, ordinal int, queue string, count int, timeout time.Duration) error {
+	current := -1
+	err := wait.PollImmediateWithContext(ctx, RetryInterval, timeout, func(ctx context.Context) (bool, error) {
+		stat, err := b.GetQueueWithContext(ctx, name, ordinal, queue)
+		if err != nil || stat == nil {
+			return false, nil
+		}
+		current = stat.MessageCount
+		return current == count, nil
+	})
+	if err != nil {
+		return fmt.Errorf("queue %s on %s has %d messages, expected %d: %v", queue, PodName(name, ordinal), current, count, err)
+	}
+	return nil
+}
+
+func (b *Broker) SendMessages(name string, ordinal int, user, password, queue string, count int) error {
+	return b.SendMessagesWithContext(context.TODO(), name, ordinal, user, password, queue, count)
+}
+
+// SendMessagesWithContext sends count messages to the given queue through the broker pod with
+// the given ordinal, using the provided credentials
+func (b *Broker) SendMessagesWithContext(ctx context.Context, name string, ordinal int, user, password, queue string, count int) error {
+	_, err := b.artemis(ctx, name, ordinal, user, password, "producer",
+		"--destination", "queue://"+queue,
+		"--message-count", strconv.Itoa(count))
+	return err
+}
+
+func (b *Broker) VerifyLogin(name string, ordinal int, user, password string) error {
+	return b.VerifyLoginWithContext(context.TODO(), name, ordinal, user, password)
+}
+
+// VerifyLoginWithContext returns an error if the broker pod with the given ordinal
+// does not authenticate the given user
+func (b *Broker) VerifyLoginWithContext(ctx context.Context, name string, ordinal int, user, password string) error {
+	_, err := b.artemis(ctx, name, ordinal, user, password, "queue", "stat", "--maxRows", "1")
+	return err
+}
+
+// ParseQueueStats parses the table printed by the "artemis queue stat" command
+func ParseQueueStats(output string) ([]QueueStat, error) {
+	var columns map[string]int
+	var stats []QueueStat
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			continue
+		}
+		var cells []string
+		for _, cell := range strings.Split(strings.Trim(line, "|"), "|") {
+			cells = append(cells, strings.TrimSpace(cell))
+		}
+		if columns == nil {
+			columns = map[string]int{}
+			for i, cell := range cells {
+				columns[cell] = i
+			}
+			if _, ok := columns["NAME"]; !ok {
+				return nil, fmt.Errorf("unexpected queue stat header: %s", line)
+			}
+			continue
+		}
+		cell := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(cells) {
+				return cells[i]
+			}
+			return ""
+		}
+		number := func(column string) int {
+			n, _ := strconv.Atoi(cell(column))
+			return n
+		}
+		stats = append(stats, QueueStat{
+			Name:          cell("NAME"),
+			Address:       cell("ADDRESS"),
+			RoutingType:   strings.ToLower(cell("ROUTING_TYPE")),
+			ConsumerCount: number("CONSUMER_COUNT"),
+			MessageCount:  number("MESSAGE_COUNT"),
+			MessagesAdded: number("MESSAGES_ADDED"),
+			MessagesAcked: number("MESSAGES_ACKED"),
+		})
+	}
+	if columns == nil {
+		return nil, fmt.Errorf("unable to parse queue stat output: %s", output)
+	}
+	return stats, nil
+}
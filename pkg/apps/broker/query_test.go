@@ -0,0 +1,51 @@
+package broker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/apps/broker"
+)
+
+func TestParseQueueStats(t *testing.T) {
+	output := `Connection brokerURL = tcp://amq-ss-0:61616
+|NAME                     |ADDRESS                  |CONSUMER_COUNT |MESSAGE_COUNT |MESSAGES_ADDED |DELIVERING_COUNT |MESSAGES_ACKED |SCHEDULED_COUNT |ROUTING_TYPE |
+|DLQ                      |DLQ                      |0              |0             |0              |0                |0              |0               |ANYCAST      |
+|orders                   |orders                   |1              |10            |12             |0                |2              |0               |ANYCAST      |
+`
+	stats, err := broker.ParseQueueStats(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 queues, got: %+v", stats)
+	}
+	expected := broker.QueueStat{Name: "orders", Address: "orders", RoutingType: broker.RoutingTypeAnycast,
+		ConsumerCount: 1, MessageCount: 10, MessagesAdded: 12, MessagesAcked: 2}
+	if stats[1] != expected {
+		t.Errorf("got: %+v, expected: %+v", stats[1], expected)
+	}
+
+	if _, err := broker.ParseQueueStats("AMQ229031: Unable to validate user"); err == nil {
+		t.Errorf("expected error parsing output without table")
+	}
+}
+
+// TestWaitForQueueCancelled validates that waiting for queues stops as soon as the context is done
+func TestWaitForQueueCancelled(t *testing.T) {
+	b, _ := newFakeBroker(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if _, err := b.WaitForQueueWithContext(ctx, "amq", 0, "orders", time.Minute); err == nil {
+		t.Errorf("expected error waiting for queue")
+	}
+	if err := b.WaitForMessageCountWithContext(ctx, "amq", 0, "orders", 1, time.Minute); err == nil {
+		t.Errorf("expected error waiting for message count")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("cancelled waits took %v", elapsed)
+	}
+}
@@ -0,0 +1,136 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Status conditions reported by the broker operator on ActiveMQArtemis resources
+const (
+	ConditionDeployed = "Deployed"
+	ConditionReady    = "Ready"
+	ConditionValid    = "Valid"
+)
+
+var readyConditions = []string{ConditionValid, ConditionDeployed, ConditionReady}
+
+func (b *Broker) WaitForReady(name string) error {
+	return b.WaitForReadyWithContext(context.TODO(), name, Timeout)
+}
+
+// WaitForReadyWithContext waits for all pods from the StatefulSet of the given ActiveMQArtemis
+// to be ready, based on its deployment plan size, and for its Valid, Deployed and Ready status
+// conditions to be true. Conditions are only verified when reported by the operator, as
+// older operator versions do not populate them.
+func (b *Broker) WaitForReadyWithContext(ctx context.Context, name string, timeout time.Duration) error {
+	return b.waitFor(ctx, name, 0, timeout)
+}
+
+func (b *Broker) WaitForCluster(name string, members int) error {
+	return b.WaitForClusterWithContext(context.TODO(), name, members, Timeout)
+}
+
+// WaitForClusterWithContext waits for the given ActiveMQArtemis to be ready (see WaitForReadyWithContext)
+// and for the cluster topology, as seen by its first broker, to report the expected number of members
+func (b *Broker) WaitForClusterWithContext(ctx context.Context, name string, members int, timeout time.Duration) error {
+	return b.waitFor(ctx, name, members, timeout)
+}
+
+// waitFor polls till all readiness checks pass. On timeout, the returned
+// error describes the last check that was not satisfied.
+func (b *Broker) waitFor(ctx context.Context, name string, members int, timeout time.Duration) error {
+	var pending string
+	err := wait.PollImmediateWithContext(ctx, RetryInterval, timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		pending, err = b.pendingCondition(ctx, name, members)
+		if err != nil {
+			return false, err
+		}
+		if pending != "" {
+			log.Logf("Waiting for %s %s: %s", Kind, name, pending)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout && pending != "" {
+		return fmt.Errorf("%s %s not ready after %v: %s", Kind, name, timeout, pending)
+	}
+	if err != nil {
+		return fmt.Errorf("failed waiting for %s %s: %v", Kind, name, err)
+	}
+	log.Logf("%s %s ready", Kind, name)
+	return nil
+}
+
+// pendingCondition returns a description of the first readiness check not yet
+// satisfied by the given ActiveMQArtemis, or an empty string if all of them are
+func (b *Broker) pendingCondition(ctx context.Context, name string, members int) (string, error) {
+	u, err := b.ctx.Clients.DynClient.Resource(b.resource(artemisKind)).Namespace(b.ctx.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("%s not found", Kind), nil
+		}
+		return "", err
+	}
+	size, found, err := unstructured.NestedInt64(u.Object, "spec", "deploymentPlan", "size")
+	if err != nil {
+		return "", err
+	}
+	if !found || size == 0 {
+		size = 1
+	}
+
+	statefulSetName := StatefulSetName(name)
+	statefulSet, err := b.ctx.Clients.KubeClient.AppsV1().StatefulSets(b.ctx.Namespace).Get(ctx, statefulSetName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("statefulset %s not created", statefulSetName), nil
+		}
+		return "", err
+	}
+	if int64(statefulSet.Status.ReadyReplicas) != size {
+		return fmt.Sprintf("statefulset %s has %d/%d ready pods", statefulSetName, statefulSet.Status.ReadyReplicas, size), nil
+	}
+
+	conditions, _, err := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if err != nil {
+		return "", err
+	}
+	for _, conditionType := range readyConditions {
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["type"] != conditionType {
+				continue
+			}
+			if condition["status"] != string(metav1.ConditionTrue) {
+				return fmt.Sprintf("condition %s is %v (reason: %v, message: %v)",
+					conditionType, condition["status"], condition["reason"], condition["message"]), nil
+			}
+		}
+	}
+
+	if members > 0 {
+		if err := b.checkTopology(ctx, name, members); err != nil {
+			return fmt.Sprintf("cluster topology does not have %d members: %v", members, err), nil
+		}
+	}
+	return "", nil
+}
+
+// checkTopology verifies the cluster topology seen by the first broker has the given number of members
+func (b *Broker) checkTopology(ctx context.Context, name string, members int) error {
+	user, password, err := b.CredentialsWithContext(ctx, name)
+	if err != nil {
+		return err
+	}
+	_, err = b.artemis(ctx, name, 0, user, password, "check", "node", "--peers", strconv.Itoa(members))
+	return err
+}
@@ -0,0 +1,59 @@
+package broker_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/apps/broker"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWaitForReady(t *testing.T) {
+	defer func(interval time.Duration) { broker.RetryInterval = interval }(broker.RetryInterval)
+	broker.RetryInterval = 10 * time.Millisecond
+
+	ctx := context.Background()
+	b, ctxData := newFakeBroker(t)
+	if _, err := b.CreateWithContext(ctx, broker.NewArtemisBuilder("amq").Size(1).Build()); err != nil {
+		t.Fatalf("unexpected error creating artemis: %v", err)
+	}
+
+	if err := b.WaitForReadyWithContext(ctx, "amq", 50*time.Millisecond); err == nil || !strings.Contains(err.Error(), "statefulset amq-ss not created") {
+		t.Errorf("expected statefulset not created error, got: %v", err)
+	}
+
+	// Simulating the statefulset created by the operator
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: broker.StatefulSetName("amq"), Namespace: ctxData.Namespace},
+		Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+	}
+	if _, err := ctxData.Clients.KubeClient.AppsV1().StatefulSets(ctxData.Namespace).Create(ctx, statefulSet, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unable to create statefulset: %v", err)
+	}
+	if err := b.WaitForReadyWithContext(ctx, "amq", time.Second); err != nil {
+		t.Errorf("unexpected error waiting for artemis: %v", err)
+	}
+
+	// Simulating a condition reported by the operator
+	gvr := schema.GroupVersionResource{Group: broker.GroupName, Version: broker.DefaultVersion, Resource: broker.Resource}
+	u, err := ctxData.Clients.DynClient.Resource(gvr).Namespace(ctxData.Namespace).Get(ctx, "amq", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to retrieve artemis: %v", err)
+	}
+	conditions := []interface{}{map[string]interface{}{"type": broker.ConditionReady, "status": "False", "reason": "WaitingForAllConditions"}}
+	if err := unstructured.SetNestedSlice(u.Object, conditions, "status", "conditions"); err != nil {
+		t.Fatalf("unable to set conditions: %v", err)
+	}
+	if _, err := ctxData.Clients.DynClient.Resource(gvr).Namespace(ctxData.Namespace).Update(ctx, u, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unable to update artemis: %v", err)
+	}
+	if err := b.WaitForReadyWithContext(ctx, "amq", 50*time.Millisecond); err == nil || !strings.Contains(err.Error(), "condition Ready is False") {
+		t.Errorf("expected condition Ready error, got: %v", err)
+	}
+
+}
@@ -0,0 +1,52 @@
+package broker
+
+import (
+	"context"
+
+	"github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewScaledown returns an ActiveMQArtemisScaledown with the given name. If localOnly
+// is true, the scale down controller only watches the context's namespace.
+func NewScaledown(name string, localOnly bool) *v1beta1.ActiveMQArtemisScaledown {
+	return &v1beta1.ActiveMQArtemisScaledown{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1beta1.ActiveMQArtemisScaledownSpec{LocalOnly: localOnly},
+	}
+}
+
+func (b *Broker) CreateScaledown(scaledown *v1beta1.ActiveMQArtemisScaledown) (*v1beta1.ActiveMQArtemisScaledown, error) {
+	return b.CreateScaledownWithContext(context.TODO(), scaledown)
+}
+
+// CreateScaledownWithContext creates the given ActiveMQArtemisScaledown in the context's namespace
+func (b *Broker) CreateScaledownWithContext(ctx context.Context, scaledown *v1beta1.ActiveMQArtemisScaledown) (*v1beta1.ActiveMQArtemisScaledown, error) {
+	created := &v1beta1.ActiveMQArtemisScaledown{}
+	if err := b.create(ctx, scaledownKind, scaledown.Name, scaledown, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (b *Broker) GetScaledown(name string) (*v1beta1.ActiveMQArtemisScaledown, error) {
+	return b.GetScaledownWithContext(context.TODO(), name)
+}
+
+// GetScaledownWithContext retrieves the ActiveMQArtemisScaledown with the given name
+func (b *Broker) GetScaledownWithContext(ctx context.Context, name string) (*v1beta1.ActiveMQArtemisScaledown, error) {
+	scaledown := &v1beta1.ActiveMQArtemisScaledown{}
+	if err := b.get(ctx, scaledownKind, name, scaledown); err != nil {
+		return nil, err
+	}
+	return scaledown, nil
+}
+
+func (b *Broker) DeleteScaledown(name string) error {
+	return b.DeleteScaledownWithContext(context.TODO(), name)
+}
+
+// DeleteScaledownWithContext deletes the ActiveMQArtemisScaledown with the given name
+func (b *Broker) DeleteScaledownWithContext(ctx context.Context, name string) error {
+	return b.delete(ctx, scaledownKind, name)
+}
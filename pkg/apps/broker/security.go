@@ -0,0 +1,152 @@
+package broker
+
+import (
+	"context"
+
+	"github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Login module flags
+const (
+	FlagRequired   = "required"
+	FlagRequisite  = "requisite"
+	FlagSufficient = "sufficient"
+	FlagOptional   = "optional"
+)
+
+// Permission operation types
+const (
+	OperationCreateAddress         = "createAddress"
+	OperationDeleteAddress         = "deleteAddress"
+	OperationCreateDurableQueue    = "createDurableQueue"
+	OperationDeleteDurableQueue    = "deleteDurableQueue"
+	OperationCreateNonDurableQueue = "createNonDurableQueue"
+	OperationDeleteNonDurableQueue = "deleteNonDurableQueue"
+	OperationSend                  = "send"
+	OperationConsume               = "consume"
+	OperationBrowse                = "browse"
+	OperationManage                = "manage"
+)
+
+// SecurityBuilder helps building ActiveMQArtemisSecurity resources
+type SecurityBuilder struct {
+	security v1beta1.ActiveMQArtemisSecurity
+}
+
+// NewSecurityBuilder returns a builder for an ActiveMQArtemisSecurity with the given name
+func NewSecurityBuilder(name string) *SecurityBuilder {
+	b := &SecurityBuilder{}
+	b.security.ObjectMeta = metav1.ObjectMeta{Name: name}
+	return b
+}
+
+// AddUser adds a user to the given properties login module (created if needed)
+func (b *SecurityBuilder) AddUser(loginModule, user, password string, roles ...string) *SecurityBuilder {
+	modules := b.security.Spec.LoginModules.PropertiesLoginModules
+	for i := range modules {
+		if modules[i].Name == loginModule {
+			modules[i].Users = append(modules[i].Users, v1beta1.UserType{Name: user, Password: &password, Roles: roles})
+			return b
+		}
+	}
+	b.security.Spec.LoginModules.PropertiesLoginModules = append(modules, v1beta1.PropertiesLoginModuleType{
+		Name:  loginModule,
+		Users: []v1beta1.UserType{{Name: user, Password: &password, Roles: roles}},
+	})
+	return b
+}
+
+// AddGuestLoginModule adds a guest login module, mapping unauthenticated users to the given user and role
+func (b *SecurityBuilder) AddGuestLoginModule(loginModule, user, role string) *SecurityBuilder {
+	b.security.Spec.LoginModules.GuestLoginModules = append(b.security.Spec.LoginModules.GuestLoginModules, v1beta1.GuestLoginModuleType{
+		Name:      loginModule,
+		GuestUser: &user,
+		GuestRole: &role,
+	})
+	return b
+}
+
+// BrokerDomain defines the broker security domain, using the given login modules (flagged as sufficient)
+func (b *SecurityBuilder) BrokerDomain(name string, loginModules ...string) *SecurityBuilder {
+	b.security.Spec.SecurityDomains.BrokerDomain = domain(name, loginModules)
+	return b
+}
+
+// ConsoleDomain defines the console security domain, using the given login modules (flagged as sufficient)
+func (b *SecurityBuilder) ConsoleDomain(name string, loginModules ...string) *SecurityBuilder {
+	b.security.Spec.SecurityDomains.ConsoleDomain = domain(name, loginModules)
+	return b
+}
+
+// AddPermission grants the given operation type to the roles, on addresses matching the given pattern
+func (b *SecurityBuilder) AddPermission(match, operationType string, roles ...string) *SecurityBuilder {
+	settings := b.security.Spec.SecuritySettings.Broker
+	permission := v1beta1.PermissionType{OperationType: operationType, Roles: roles}
+	for i := range settings {
+		if settings[i].Match == match {
+			settings[i].Permissions = append(settings[i].Permissions, permission)
+			return b
+		}
+	}
+	b.security.Spec.SecuritySettings.Broker = append(settings, v1beta1.BrokerSecuritySettingType{
+		Match:       match,
+		Permissions: []v1beta1.PermissionType{permission},
+	})
+	return b
+}
+
+// ApplyTo restricts the security settings to the given ActiveMQArtemis resources
+func (b *SecurityBuilder) ApplyTo(crNames ...string) *SecurityBuilder {
+	b.security.Spec.ApplyToCrNames = append(b.security.Spec.ApplyToCrNames, crNames...)
+	return b
+}
+
+// Build returns a copy of the ActiveMQArtemisSecurity being built
+func (b *SecurityBuilder) Build() *v1beta1.ActiveMQArtemisSecurity {
+	return b.security.DeepCopy()
+}
+
+func domain(name string, loginModules []string) v1beta1.BrokerDomainType {
+	d := v1beta1.BrokerDomainType{Name: &name}
+	for _, module := range loginModules {
+		moduleName, flag := module, FlagSufficient
+		d.LoginModules = append(d.LoginModules, v1beta1.LoginModuleReferenceType{Name: &moduleName, Flag: &flag})
+	}
+	return d
+}
+
+func (b *Broker) CreateSecurity(security *v1beta1.ActiveMQArtemisSecurity) (*v1beta1.ActiveMQArtemisSecurity, error) {
+	return b.CreateSecurityWithContext(context.TODO(), security)
+}
+
+// CreateSecurityWithContext creates the given ActiveMQArtemisSecurity in the context's namespace
+func (b *Broker) CreateSecurityWithContext(ctx context.Context, security *v1beta1.ActiveMQArtemisSecurity) (*v1beta1.ActiveMQArtemisSecurity, error) {
+	created := &v1beta1.ActiveMQArtemisSecurity{}
+	if err := b.create(ctx, securityKind, security.Name, security, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (b *Broker) GetSecurity(name string) (*v1beta1.ActiveMQArtemisSecurity, error) {
+	return b.GetSecurityWithContext(context.TODO(), name)
+}
+
+// GetSecurityWithContext retrieves the ActiveMQArtemisSecurity with the given name
+func (b *Broker) GetSecurityWithContext(ctx context.Context, name string) (*v1beta1.ActiveMQArtemisSecurity, error) {
+	security := &v1beta1.ActiveMQArtemisSecurity{}
+	if err := b.get(ctx, securityKind, name, security); err != nil {
+		return nil, err
+	}
+	return security, nil
+}
+
+func (b *Broker) DeleteSecurity(name string) error {
+	return b.DeleteSecurityWithContext(context.TODO(), name)
+}
+
+// DeleteSecurityWithContext deletes the ActiveMQArtemisSecurity with the given name
+func (b *Broker) DeleteSecurityWithContext(ctx context.Context, name string) error {
+	return b.delete(ctx, securityKind, name)
+}
@@ -0,0 +1,119 @@
+package qdr
+
+import (
+	"github.com/rh-messaging/shipshape/pkg/api/interconnect/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InterconnectBuilder helps building Interconnect resources
+type InterconnectBuilder struct {
+	interconnect v1alpha1.Interconnect
+}
+
+// NewInterconnectBuilder returns a builder for an Interconnect with the given name,
+// defaulting to a single interior router
+func NewInterconnectBuilder(name string) *InterconnectBuilder {
+	b := &InterconnectBuilder{}
+	b.interconnect.ObjectMeta = metav1.ObjectMeta{Name: name}
+	b.interconnect.Spec.DeploymentPlan.Size = 1
+	b.interconnect.Spec.DeploymentPlan.Role = v1alpha1.RouterRoleInterior
+	b.interconnect.Spec.DeploymentPlan.Placement = v1alpha1.PlacementAny
+	return b
+}
+
+func (b *InterconnectBuilder) Size(size int32) *InterconnectBuilder {
+	b.interconnect.Spec.DeploymentPlan.Size = size
+	return b
+}
+
+func (b *InterconnectBuilder) Image(image string) *InterconnectBuilder {
+	b.interconnect.Spec.DeploymentPlan.Image = image
+	return b
+}
+
+func (b *InterconnectBuilder) Interior() *InterconnectBuilder {
+	b.interconnect.Spec.DeploymentPlan.Role = v1alpha1.RouterRoleInterior
+	return b
+}
+
+func (b *InterconnectBuilder) Edge() *InterconnectBuilder {
+	b.interconnect.Spec.DeploymentPlan.Role = v1alpha1.RouterRoleEdge
+	return b
+}
+
+func (b *InterconnectBuilder) Placement(placement v1alpha1.PlacementType) *InterconnectBuilder {
+	b.interconnect.Spec.DeploymentPlan.Placement = placement
+	return b
+}
+
+func (b *InterconnectBuilder) Labels(labels map[string]string) *InterconnectBuilder {
+	b.interconnect.Labels = labels
+	return b
+}
+
+func (b *InterconnectBuilder) AddListener(listener v1alpha1.Listener) *InterconnectBuilder {
+	b.interconnect.Spec.Listeners = append(b.interconnect.Spec.Listeners, listener)
+	return b
+}
+
+func (b *InterconnectBuilder) AddInterRouterListener(listener v1alpha1.Listener) *InterconnectBuilder {
+	b.interconnect.Spec.InterRouterListeners = append(b.interconnect.Spec.InterRouterListeners, listener)
+	return b
+}
+
+func (b *InterconnectBuilder) AddEdgeListener(listener v1alpha1.Listener) *InterconnectBuilder {
+	b.interconnect.Spec.EdgeListeners = append(b.interconnect.Spec.EdgeListeners, listener)
+	return b
+}
+
+func (b *InterconnectBuilder) AddConnector(connector v1alpha1.Connector) *InterconnectBuilder {
+	b.interconnect.Spec.Connectors = append(b.interconnect.Spec.Connectors, connector)
+	return b
+}
+
+func (b *InterconnectBuilder) AddInterRouterConnector(connector v1alpha1.Connector) *InterconnectBuilder {
+	b.interconnect.Spec.InterRouterConnectors = append(b.interconnect.Spec.InterRouterConnectors, connector)
+	return b
+}
+
+func (b *InterconnectBuilder) AddEdgeConnector(connector v1alpha1.Connector) *InterconnectBuilder {
+	b.interconnect.Spec.EdgeConnectors = append(b.interconnect.Spec.EdgeConnectors, connector)
+	return b
+}
+
+func (b *InterconnectBuilder) AddSslProfile(profile v1alpha1.SslProfile) *InterconnectBuilder {
+	b.interconnect.Spec.SslProfiles = append(b.interconnect.Spec.SslProfiles, profile)
+	return b
+}
+
+func (b *InterconnectBuilder) AddAddress(address v1alpha1.Address) *InterconnectBuilder {
+	b.interconnect.Spec.Addresses = append(b.interconnect.Spec.Addresses, address)
+	return b
+}
+
+func (b *InterconnectBuilder) AddAutoLink(autoLink v1alpha1.AutoLink) *InterconnectBuilder {
+	b.interconnect.Spec.AutoLinks = append(b.interconnect.Spec.AutoLinks, autoLink)
+	return b
+}
+
+func (b *InterconnectBuilder) AddLinkRoute(linkRoute v1alpha1.LinkRoute) *InterconnectBuilder {
+	b.interconnect.Spec.LinkRoutes = append(b.interconnect.Spec.LinkRoutes, linkRoute)
+	return b
+}
+
+// Build returns a copy of the Interconnect being built
+func (b *InterconnectBuilder) Build() *v1alpha1.Interconnect {
+	interconnect := b.interconnect
+	spec := &interconnect.Spec
+	spec.Listeners = append([]v1alpha1.Listener{}, spec.Listeners...)
+	spec.InterRouterListeners = append([]v1alpha1.Listener{}, spec.InterRouterListeners...)
+	spec.EdgeListeners = append([]v1alpha1.Listener{}, spec.EdgeListeners...)
+	spec.Connectors = append([]v1alpha1.Connector{}, spec.Connectors...)
+	spec.InterRouterConnectors = append([]v1alpha1.Connector{}, spec.InterRouterConnectors...)
+	spec.EdgeConnectors = append([]v1alpha1.Connector{}, spec.EdgeConnectors...)
+	spec.SslProfiles = append([]v1alpha1.SslProfile{}, spec.SslProfiles...)
+	spec.Addresses = append([]v1alpha1.Address{}, spec.Addresses...)
+	spec.AutoLinks = append([]v1alpha1.AutoLink{}, spec.AutoLinks...)
+	spec.LinkRoutes = append([]v1alpha1.LinkRoute{}, spec.LinkRoutes...)
+	return &interconnect
+}
@@ -0,0 +1,196 @@
+// Package qdr provides helpers to manage Interconnect custom resources
+// (Qpid Dispatch router networks) through the qdr operator set up by the framework.
+package qdr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/api/interconnect/v1alpha1"
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	RetryInterval = 5 * time.Second
+	Timeout       = 5 * time.Minute
+)
+
+// Qdr manages Interconnect resources in the namespace of the given context
+type Qdr struct {
+	ctx *framework.ContextData
+}
+
+func NewQdr(ctx *framework.ContextData) *Qdr {
+	return &Qdr{ctx: ctx}
+}
+
+func (q *Qdr) GetOperator() operators.OperatorSetup {
+	return q.ctx.OperatorMap[operators.OperatorTypeQdr]
+}
+
+func (q *Qdr) interconnects() v1alpha1.InterconnectInterface {
+	return v1alpha1.New(q.ctx.Clients.DynClient).Interconnects(q.ctx.Namespace)
+}
+
+func (q *Qdr) Create(interconnect *v1alpha1.Interconnect) (*v1alpha1.Interconnect, error) {
+	return q.CreateWithContext(context.TODO(), interconnect)
+}
+
+// CreateWithContext creates the given Interconnect in the context's namespace
+func (q *Qdr) CreateWithContext(ctx context.Context, interconnect *v1alpha1.Interconnect) (*v1alpha1.Interconnect, error) {
+	u, err := v1alpha1.ToUnstructured(interconnect)
+	if err != nil {
+		return nil, err
+	}
+	created, err := q.ctx.CreateResourceGroupVersionWithContext(ctx, v1alpha1.SchemeGroupVersionResource, u, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s %s: %v", v1alpha1.Kind, interconnect.Name, err)
+	}
+	return v1alpha1.FromUnstructured(created)
+}
+
+func (q *Qdr) Update(interconnect *v1alpha1.Interconnect) (*v1alpha1.Interconnect, error) {
+	return q.UpdateWithContext(context.TODO(), interconnect)
+}
+
+// UpdateWithContext updates the given Interconnect (its ResourceVersion
+// must match the current one)
+func (q *Qdr) UpdateWithContext(ctx context.Context, interconnect *v1alpha1.Interconnect) (*v1alpha1.Interconnect, error) {
+	updated, err := q.interconnects().Update(ctx, interconnect, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update %s %s: %v", v1alpha1.Kind, interconnect.Name, err)
+	}
+	return updated, nil
+}
+
+func (q *Qdr) Get(name string) (*v1alpha1.Interconnect, error) {
+	return q.GetWithContext(context.TODO(), name)
+}
+
+// GetWithContext retrieves the Interconnect with the given name
+func (q *Qdr) GetWithContext(ctx context.Context, name string) (*v1alpha1.Interconnect, error) {
+	return q.interconnects().Get(ctx, name, metav1.GetOptions{})
+}
+
+func (q *Qdr) Delete(name string) error {
+	return q.DeleteWithContext(context.TODO(), name)
+}
+
+// DeleteWithContext deletes the Interconnect with the given name
+func (q *Qdr) DeleteWithContext(ctx context.Context, name string) error {
+	return q.interconnects().Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+func (q *Qdr) PodNames(name string) ([]string, error) {
+	return q.PodNamesWithContext(context.TODO(), name)
+}
+
+// PodNamesWithContext returns the names of the router pods of the given Interconnect
+func (q *Qdr) PodNamesWithContext(ctx context.Context, name string) ([]string, error) {
+	pods, err := q.ctx.Clients.KubeClient.CoreV1().Pods(q.ctx.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "application=" + name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	return names, nil
+}
+
+func (q *Qdr) WaitForReady(name string) error {
+	return q.WaitForReadyWithContext(context.TODO(), name, Timeout)
+}
+
+// WaitForReadyWithContext waits for all routers of the given Interconnect to be
+// available (based on its deployment plan size and placement) and reported in its status.
+// On timeout, the returned error describes the last check that was not satisfied.
+func (q *Qdr) WaitForReadyWithContext(ctx context.Context, name string, timeout time.Duration) error {
+	var pending string
+	err := wait.PollImmediateWithContext(ctx, RetryInterval, timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		pending, err = q.pendingCondition(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		if pending != "" {
+			log.Logf("Waiting for %s %s: %s", v1alpha1.Kind, name, pending)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout && pending != "" {
+		return fmt.Errorf("%s %s not ready after %v: %s", v1alpha1.Kind, name, timeout, pending)
+	}
+	if err != nil {
+		return fmt.Errorf("failed waiting for %s %s: %v", v1alpha1.Kind, name, err)
+	}
+	log.Logf("%s %s ready", v1alpha1.Kind, name)
+	return nil
+}
+
+func (q *Qdr) WaitForMesh(names ...string) error {
+	return q.WaitForMeshWithContext(context.TODO(), Timeout, names...)
+}
+
+// WaitForMeshWithContext waits for all routers of the given Interconnects to be ready
+func (q *Qdr) WaitForMeshWithContext(ctx context.Context, timeout time.Duration, names ...string) error {
+	for _, name := range names {
+		if err := q.WaitForReadyWithContext(ctx, name, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingCondition returns a description of the first readiness check not yet
+// satisfied by the given Interconnect, or an empty string if all of them are
+func (q *Qdr) pendingCondition(ctx context.Context, name string) (string, error) {
+	interconnect, err := q.GetWithContext(ctx, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("%s not found", v1alpha1.Kind), nil
+		}
+		return "", err
+	}
+
+	var expected, ready int32
+	apps := q.ctx.Clients.KubeClient.AppsV1()
+	if interconnect.Spec.DeploymentPlan.Placement == v1alpha1.PlacementEvery {
+		daemonSet, err := apps.DaemonSets(q.ctx.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("daemonset %s not created", name), nil
+			}
+			return "", err
+		}
+		expected, ready = daemonSet.Status.DesiredNumberScheduled, daemonSet.Status.NumberReady
+	} else {
+		deployment, err := apps.Deployments(q.ctx.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("deployment %s not created", name), nil
+			}
+			return "", err
+		}
+		expected, ready = interconnect.Spec.DeploymentPlan.Size, deployment.Status.ReadyReplicas
+		if expected == 0 {
+			expected = 1
+		}
+	}
+	if ready != expected || expected == 0 {
+		return fmt.Sprintf("%d/%d routers ready", ready, expected), nil
+	}
+	if len(interconnect.Status.PodNames) != int(expected) {
+		return fmt.Sprintf("%d/%d routers reported in status", len(interconnect.Status.PodNames), expected), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,79 @@
+package qdr_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/api/interconnect/v1alpha1"
+	"github.com/rh-messaging/shipshape/pkg/apps/qdr"
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInterconnect(t *testing.T) {
+	defer func(interval time.Duration) { qdr.RetryInterval = interval }(qdr.RetryInterval)
+	qdr.RetryInterval = 10 * time.Millisecond
+
+	ctx := context.Background()
+	f, err := framework.NewFrameworkBuilder("qdr").WithContexts(framework.FakeContext).WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+	defer func() {
+		if err := f.AfterEachWithContext(ctx); err != nil {
+			t.Errorf("unexpected error on teardown: %v", err)
+		}
+	}()
+	ctxData := f.GetFirstContext()
+
+	q := qdr.NewQdr(ctxData)
+	interconnect := qdr.NewInterconnectBuilder("mesh").
+		Size(2).
+		AddListener(v1alpha1.Listener{Port: 5672}).
+		AddInterRouterListener(v1alpha1.Listener{Port: 55672}).
+		AddAddress(v1alpha1.Address{Prefix: "closest", Distribution: "closest"}).
+		Build()
+	if _, err := q.CreateWithContext(ctx, interconnect); err != nil {
+		t.Fatalf("unexpected error creating interconnect: %v", err)
+	}
+	if tracked := ctxData.Tracker.Tracked(); len(tracked) != 1 || tracked[0].GVR != v1alpha1.SchemeGroupVersionResource || tracked[0].Name != "mesh" {
+		t.Errorf("interconnect not tracked: %v", tracked)
+	}
+	created, err := q.GetWithContext(ctx, "mesh")
+	if err != nil {
+		t.Fatalf("unexpected error retrieving interconnect: %v", err)
+	}
+	if created.Spec.DeploymentPlan.Size != 2 || created.Spec.DeploymentPlan.Role != v1alpha1.RouterRoleInterior {
+		t.Errorf("unexpected deployment plan: %+v", created.Spec.DeploymentPlan)
+	}
+	if len(created.Spec.Listeners) != 1 || len(created.Spec.InterRouterListeners) != 1 || len(created.Spec.Addresses) != 1 {
+		t.Errorf("unexpected spec: %+v", created.Spec)
+	}
+
+	// Simulating the deployment created by the operator
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: ctxData.Namespace},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+	}
+	if _, err := ctxData.Clients.KubeClient.AppsV1().Deployments(ctxData.Namespace).Create(ctx, deployment, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unable to create deployment: %v", err)
+	}
+	if err := q.WaitForReadyWithContext(ctx, "mesh", 50*time.Millisecond); err == nil || !strings.Contains(err.Error(), "0/2 routers reported in status") {
+		t.Errorf("expected routers not reported error, got: %v", err)
+	}
+
+	created.Status.PodNames = []string{"mesh-0", "mesh-1"}
+	if _, err := q.UpdateWithContext(ctx, created); err != nil {
+		t.Fatalf("unexpected error updating interconnect: %v", err)
+	}
+	if err := q.WaitForMeshWithContext(ctx, time.Second, "mesh"); err != nil {
+		t.Errorf("unexpected error waiting for mesh: %v", err)
+	}
+
+	if err := q.DeleteWithContext(ctx, "mesh"); err != nil {
+		t.Errorf("unexpected error deleting interconnect: %v", err)
+	}
+}
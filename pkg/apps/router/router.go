@@ -0,0 +1,241 @@
+// Package router provides helpers to inspect a Qpid Dispatch router network
+// (deployed through the qdr operator or by skupper), running qdstat and
+// qdmanage against the router pods and parsing their output.
+package router
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	RetryInterval = 5 * time.Second
+	Timeout       = 2 * time.Minute
+)
+
+// Router runs management commands against the router running on a given pod
+type Router struct {
+	ctx *framework.ContextData
+	pod string
+	// Args are appended to all qdstat and qdmanage commands (like the
+	// --bus, --ssl-certificate or --sasl options needed to reach the router)
+	Args []string
+}
+
+// NewRouter returns a Router for the given pod, from the context's namespace
+func NewRouter(ctx *framework.ContextData, pod string, args ...string) *Router {
+	return &Router{ctx: ctx, pod: pod, Args: args}
+}
+
+func (r *Router) Pod() string {
+	return r.pod
+}
+
+func (r *Router) run(ctx context.Context, command string, args ...string) (string, error) {
+	args = append(args, r.Args...)
+	stdout, stderr, err := r.ctx.ExecuteWithContext(ctx, command, args, r.pod)
+	if err != nil {
+		return "", fmt.Errorf("%s failed on %s: %v: %s", command, r.pod, err, stdout+stderr)
+	}
+	return stdout, nil
+}
+
+func (r *Router) Qdstat(args ...string) (string, error) {
+	return r.QdstatWithContext(context.TODO(), args...)
+}
+
+// QdstatWithContext runs qdstat with the given arguments, returning its output
+func (r *Router) QdstatWithContext(ctx context.Context, args ...string) (string, error) {
+	return r.run(ctx, "qdstat", args...)
+}
+
+func (r *Router) Qdmanage(args ...string) (string, error) {
+	return r.QdmanageWithContext(context.TODO(), args...)
+}
+
+// QdmanageWithContext runs qdmanage with the given arguments, returning its output
+func (r *Router) QdmanageWithContext(ctx context.Context, args ...string) (string, error) {
+	return r.run(ctx, "qdmanage", args...)
+}
+
+// query returns the output of a qdmanage query for the given entity type
+func (r *Router) query(ctx context.Context, entityType string) (string, error) {
+	return r.QdmanageWithContext(ctx, "query", "--type", entityType)
+}
+
+func (r *Router) Connections() ([]Connection, error) {
+	return r.ConnectionsWithContext(context.TODO())
+}
+
+// ConnectionsWithContext returns the connections of the router
+func (r *Router) ConnectionsWithContext(ctx context.Context) ([]Connection, error) {
+	output, err := r.query(ctx, EntityConnection)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConnections(output)
+}
+
+func (r *Router) Links() ([]Link, error) {
+	return r.LinksWithContext(context.TODO())
+}
+
+// LinksWithContext returns the links of the router
+func (r *Router) LinksWithContext(ctx context.Context) ([]Link, error) {
+	output, err := r.query(ctx, EntityLink)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLinks(output)
+}
+
+func (r *Router) Addresses() ([]Address, error) {
+	return r.AddressesWithContext(context.TODO())
+}
+
+// AddressesWithContext returns the addresses known to the router
+func (r *Router) AddressesWithContext(ctx context.Context) ([]Address, error) {
+	output, err := r.query(ctx, EntityAddress)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAddresses(output)
+}
+
+func (r *Router) Nodes() ([]Node, error) {
+	return r.NodesWithContext(context.TODO())
+}
+
+// NodesWithContext returns the interior routers known to the router (including itself)
+func (r *Router) NodesWithContext(ctx context.Context) ([]Node, error) {
+	output, err := r.query(ctx, EntityNode)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNodes(output)
+}
+
+func (r *Router) AutoLinks() ([]AutoLink, error) {
+	return r.AutoLinksWithContext(context.TODO())
+}
+
+// AutoLinksWithContext returns the auto links configured on the router
+func (r *Router) AutoLinksWithContext(ctx context.Context) ([]AutoLink, error) {
+	output, err := r.query(ctx, EntityAutoLink)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAutoLinks(output)
+}
+
+// CountConnections returns the number of opened connections with the given role
+func CountConnections(connections []Connection, role string) int {
+	count := 0
+	for _, connection := range connections {
+		if connection.Role == role && connection.Opened {
+			count++
+		}
+	}
+	return count
+}
+
+// WaitForWithContext polls the router until the given condition is met, logging query errors.
+// On timeout, the returned error includes the given description.
+func (r *Router) WaitForWithContext(ctx context.Context, description string, timeout time.Duration, condition func(ctx context.Context) (bool, error)) error {
+	var lastErr error
+	err := wait.PollImmediateWithContext(ctx, RetryInterval, timeout, func(ctx context.Context) (bool, error) {
+		done, err := condition(ctx)
+		if err != nil {
+			lastErr = err
+			log.Logf("Waiting for %s on router %s: %v", description, r.pod, err)
+			return false, nil
+		}
+		return done, nil
+	})
+	if err != nil {
+		if lastErr != nil {
+			return fmt.Errorf("router %s: timed out waiting for %s: %v (last error: %v)", r.pod, description, err, lastErr)
+		}
+		return fmt.Errorf("router %s: timed out waiting for %s: %v", r.pod, description, err)
+	}
+	return nil
+}
+
+func (r *Router) WaitForConnections(role string, count int) error {
+	return r.WaitForConnectionsWithContext(context.TODO(), role, count, Timeout)
+}
+
+// WaitForConnectionsWithContext waits until the router has exactly the given
+// number of opened connections with the given role (like RoleInterRouter)
+func (r *Router) WaitForConnectionsWithContext(ctx context.Context, role string, count int, timeout time.Duration) error {
+	return r.WaitForWithContext(ctx, fmt.Sprintf("%d %s connections", count, role), timeout, func(ctx context.Context) (bool, error) {
+		connections, err := r.ConnectionsWithContext(ctx)
+		if err != nil {
+			return false, err
+		}
+		current := CountConnections(connections, role)
+		log.Logf("Router %s has %d/%d %s connections", r.pod, current, count, role)
+		return current == count, nil
+	})
+}
+
+func (r *Router) WaitForNodes(count int) error {
+	return r.WaitForNodesWithContext(context.TODO(), count, Timeout)
+}
+
+// WaitForNodesWithContext waits until the router knows the given number of interior routers (including itself)
+func (r *Router) WaitForNodesWithContext(ctx context.Context, count int, timeout time.Duration) error {
+	return r.WaitForWithContext(ctx, fmt.Sprintf("%d nodes", count), timeout, func(ctx context.Context) (bool, error) {
+		nodes, err := r.NodesWithContext(ctx)
+		if err != nil {
+			return false, err
+		}
+		return len(nodes) == count, nil
+	})
+}
+
+func (r *Router) WaitForAddress(address string, subscribers int) error {
+	return r.WaitForAddressWithContext(context.TODO(), address, subscribers, Timeout)
+}
+
+// WaitForAddressWithContext waits until the given address (as reported by the router, including its
+// class prefix, like "M0myaddress") has at least the given number of local and remote subscribers
+func (r *Router) WaitForAddressWithContext(ctx context.Context, address string, subscribers int, timeout time.Duration) error {
+	return r.WaitForWithContext(ctx, fmt.Sprintf("address %s with %d subscribers", address, subscribers), timeout, func(ctx context.Context) (bool, error) {
+		addresses, err := r.AddressesWithContext(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, a := range addresses {
+			if a.Name == address {
+				return int(a.SubscriberCount+a.RemoteCount) >= subscribers, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+func (r *Router) WaitForAutoLinksActive() error {
+	return r.WaitForAutoLinksActiveWithContext(context.TODO(), Timeout)
+}
+
+// WaitForAutoLinksActiveWithContext waits until all auto links configured on the router are active
+func (r *Router) WaitForAutoLinksActiveWithContext(ctx context.Context, timeout time.Duration) error {
+	return r.WaitForWithContext(ctx, "active auto links", timeout, func(ctx context.Context) (bool, error) {
+		autoLinks, err := r.AutoLinksWithContext(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, autoLink := range autoLinks {
+			if autoLink.OperStatus != "active" {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
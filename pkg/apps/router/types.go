@@ -0,0 +1,162 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Management entity types queried through qdmanage
+const (
+	EntityConnection = "connection"
+	EntityLink       = "router.link"
+	EntityAddress    = "router.address"
+	EntityNode       = "router.node"
+	EntityAutoLink   = "router.config.autoLink"
+)
+
+// Connection roles
+const (
+	RoleNormal         = "normal"
+	RoleInterRouter    = "inter-router"
+	RoleEdge           = "edge"
+	RoleRouteContainer = "route-container"
+)
+
+// ID is a management identifier, reported either as a string or as a number
+type ID string
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case nil:
+		*id = ""
+	case string:
+		*id = ID(v)
+	default:
+		*id = ID(strings.Trim(string(data), `"`))
+	}
+	return nil
+}
+
+type Connection struct {
+	Identity        ID     `json:"identity"`
+	Name            string `json:"name"`
+	Host            string `json:"host"`
+	Role            string `json:"role"`
+	Dir             string `json:"dir"`
+	Container       string `json:"container"`
+	Opened          bool   `json:"opened"`
+	OperStatus      string `json:"operStatus"`
+	Sasl            string `json:"sasl"`
+	IsAuthenticated bool   `json:"isAuthenticated"`
+	IsEncrypted     bool   `json:"isEncrypted"`
+	User            string `json:"user"`
+	UptimeSeconds   int64  `json:"uptimeSeconds"`
+}
+
+type Link struct {
+	Identity         ID     `json:"identity"`
+	LinkName         string `json:"linkName"`
+	LinkType         string `json:"linkType"`
+	LinkDir          string `json:"linkDir"`
+	OwningAddr       string `json:"owningAddr"`
+	ConnectionID     ID     `json:"connectionId"`
+	Capacity         int64  `json:"capacity"`
+	UndeliveredCount int64  `json:"undeliveredCount"`
+	UnsettledCount   int64  `json:"unsettledCount"`
+	DeliveryCount    int64  `json:"deliveryCount"`
+	PresettledCount  int64  `json:"presettledCount"`
+	AcceptedCount    int64  `json:"acceptedCount"`
+	RejectedCount    int64  `json:"rejectedCount"`
+	ReleasedCount    int64  `json:"releasedCount"`
+	ModifiedCount    int64  `json:"modifiedCount"`
+	AdminStatus      string `json:"adminStatus"`
+	OperStatus       string `json:"operStatus"`
+}
+
+type Address struct {
+	Identity          ID     `json:"identity"`
+	Name              string `json:"name"`
+	Distribution      string `json:"distribution"`
+	InProcess         int64  `json:"inProcess"`
+	SubscriberCount   int64  `json:"subscriberCount"`
+	RemoteCount       int64  `json:"remoteCount"`
+	ContainerCount    int64  `json:"containerCount"`
+	DeliveriesIngress int64  `json:"deliveriesIngress"`
+	DeliveriesEgress  int64  `json:"deliveriesEgress"`
+	DeliveriesTransit int64  `json:"deliveriesTransit"`
+}
+
+type Node struct {
+	Identity   ID       `json:"identity"`
+	ID         string   `json:"id"`
+	Address    string   `json:"address"`
+	NextHop    string   `json:"nextHop"`
+	RouterLink ID       `json:"routerLink"`
+	Cost       int64    `json:"cost"`
+	LinkState  []string `json:"linkState"`
+}
+
+type AutoLink struct {
+	Identity        ID     `json:"identity"`
+	Name            string `json:"name"`
+	Address         string `json:"address"`
+	Direction       string `json:"direction"`
+	ContainerID     string `json:"containerId"`
+	Connection      string `json:"connection"`
+	ExternalAddress string `json:"externalAddress"`
+	OperStatus      string `json:"operStatus"`
+	LastError       string `json:"lastError"`
+}
+
+// ParseConnections parses the output of a qdmanage connection query
+func ParseConnections(output string) ([]Connection, error) {
+	var connections []Connection
+	err := parse(output, &connections)
+	return connections, err
+}
+
+// ParseLinks parses the output of a qdmanage router.link query
+func ParseLinks(output string) ([]Link, error) {
+	var links []Link
+	err := parse(output, &links)
+	return links, err
+}
+
+// ParseAddresses parses the output of a qdmanage router.address query
+func ParseAddresses(output string) ([]Address, error) {
+	var addresses []Address
+	err := parse(output, &addresses)
+	return addresses, err
+}
+
+// ParseNodes parses the output of a qdmanage router.node query
+func ParseNodes(output string) ([]Node, error) {
+	var nodes []Node
+	err := parse(output, &nodes)
+	return nodes, err
+}
+
+// ParseAutoLinks parses the output of a qdmanage router.config.autoLink query
+func ParseAutoLinks(output string) ([]AutoLink, error) {
+	var autoLinks []AutoLink
+	err := parse(output, &autoLinks)
+	return autoLinks, err
+}
+
+// parse unmarshals the JSON list printed by qdmanage, ignoring anything
+// printed before it (like warnings)
+func parse(output string, value interface{}) error {
+	start := strings.Index(output, "[")
+	if start < 0 {
+		return fmt.Errorf("unable to parse qdmanage output: %s", output)
+	}
+	if err := json.Unmarshal([]byte(output[start:]), value); err != nil {
+		return fmt.Errorf("unable to parse qdmanage output: %v: %s", err, output)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/rh-messaging/shipshape/pkg/apps/router"
+)
+
+func TestParseConnections(t *testing.T) {
+	output := `[
+  {
+    "identity": "3",
+    "host": "10.128.2.15:55672",
+    "role": "inter-router",
+    "dir": "in",
+    "container": "mesh-0",
+    "opened": true,
+    "operStatus": "up",
+    "isEncrypted": true,
+    "uptimeSeconds": 42
+  },
+  {
+    "identity": 7,
+    "host": "127.0.0.1:45678",
+    "role": "normal",
+    "dir": "in",
+    "opened": true
+  }
+]`
+	connections, err := router.ParseConnections(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(connections) != 2 {
+		t.Fatalf("expected 2 connections, got: %+v", connections)
+	}
+	if connections[0].Identity != "3" || connections[1].Identity != "7" {
+		t.Errorf("unexpected identities: %s, %s", connections[0].Identity, connections[1].Identity)
+	}
+	if !connections[0].IsEncrypted || connections[0].UptimeSeconds != 42 {
+		t.Errorf("unexpected connection: %+v", connections[0])
+	}
+	if count := router.CountConnections(connections, router.RoleInterRouter); count != 1 {
+		t.Errorf("expected 1 inter-router connection, got: %d", count)
+	}
+
+	if _, err := router.ParseConnections("ConnectionException: Connection refused"); err == nil {
+		t.Errorf("expected error parsing output without entities")
+	}
+}
+
+func TestParseNodes(t *testing.T) {
+	output := `[{"identity": "router.node/mesh-1", "id": "mesh-1", "nextHop": "(self)", "routerLink": null, "cost": 1, "linkState": ["mesh-0"]}]`
+	nodes, err := router.ParseNodes(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].ID != "mesh-1" || nodes[0].RouterLink != "" || len(nodes[0].LinkState) != 1 {
+		t.Errorf("unexpected nodes: %+v", nodes)
+	}
+}
@@ -0,0 +1,119 @@
+package skupper_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/apps/skupper"
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+)
+
+// newFakeSkupper returns a Skupper instance running a fake skupper binary, which
+// echoes and records its arguments (into the returned file) or sleeps if asked to
+func newFakeSkupper(t *testing.T) (*skupper.Skupper, string) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+  *sleep*) exec sleep 5 ;;
+  *) echo "$@" | tee -a "$(dirname "$0")/args" ;;
+esac
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "skupper"), []byte(script), 0755); err != nil {
+		t.Fatalf("unable to write fake skupper: %v", err)
+	}
+	ctx := &framework.ContextData{
+		Id:        "ctx1",
+		Namespace: "east",
+		OperatorMap: map[operators.OperatorType]operators.OperatorSetup{
+			operators.OperatorTypeSkupper: &operators.SkupperOperator{SkupperPath: dir + "/"},
+		},
+	}
+	return skupper.NewSkupper(ctx), filepath.Join(dir, "args")
+}
+
+func TestCommandArgs(t *testing.T) {
+	s, argsFile := newFakeSkupper(t)
+	for _, tc := range []struct {
+		run      func() error
+		expected string
+	}{{
+		func() error {
+			return s.GatewayInit(skupper.GatewayInitFlags{Name: "gw1", Type: "podman", Config: "gateway.yaml"})
+		},
+		"gateway init --name gw1 --type podman --config gateway.yaml",
+	}, {
+		func() error { return s.GatewayInit(skupper.GatewayInitFlags{}) },
+		"gateway init",
+	}, {
+		func() error {
+			return s.GatewayExpose("db", "10.0.0.1", 5432, skupper.GatewayExposeFlags{Protocol: "tcp", Type: "docker", Aggregate: "json", TargetPort: 15432})
+		},
+		"gateway expose db 10.0.0.1 5432 --protocol tcp --type docker --aggregate json --target-port 15432",
+	}, {
+		func() error { return s.GatewayDelete() },
+		"gateway delete",
+	}, {
+		func() error { return s.LinkCreate("/tmp/token.yaml", skupper.LinkCreateFlags{Name: "link1", Cost: 2}) },
+		"link create /tmp/token.yaml --name link1 --cost 2",
+	}, {
+		func() error { return s.LinkCreate("/tmp/token.yaml", skupper.LinkCreateFlags{}) },
+		"link create /tmp/token.yaml",
+	}, {
+		func() error { return s.LinkDelete("link1") },
+		"link delete link1",
+	}, {
+		func() error {
+			return s.ServiceCreate("backend", 8080, skupper.ServiceCreateFlags{Protocol: "http", Aggregate: "multipart", EventChannel: true, EnableTLS: true})
+		},
+		"service create backend 8080 --protocol http --aggregate multipart --event-channel --enable-tls",
+	}, {
+		func() error { return s.ServiceDelete("backend") },
+		"service delete backend",
+	}, {
+		func() error {
+			return s.ServiceBind("backend", "deployment", "backend", skupper.ServiceBindFlags{Protocol: "http", TargetPort: 8081})
+		},
+		"service bind backend deployment backend --protocol http --target-port 8081",
+	}, {
+		func() error { return s.ServiceUnbind("backend", "deployment", "backend") },
+		"service unbind backend deployment backend",
+	}, {
+		func() error {
+			return s.TokenCreate("/tmp/token.yaml", skupper.TokenCreateFlags{Name: "token1", TokenType: skupper.TokenTypeCert, Expiry: 30 * time.Minute, Uses: 3, Password: "secret"})
+		},
+		"token create /tmp/token.yaml --name token1 --token-type cert --expiry 30m0s --uses 3 --password secret",
+	}, {
+		func() error { return s.TokenCreate("/tmp/token.yaml", skupper.TokenCreateFlags{}) },
+		"token create /tmp/token.yaml",
+	}} {
+		os.Remove(argsFile)
+		if err := tc.run(); err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.expected, err)
+			continue
+		}
+		args, err := ioutil.ReadFile(argsFile)
+		if err != nil {
+			t.Fatalf("%s: unable to read arguments: %v", tc.expected, err)
+		}
+		if expected := tc.expected + " --namespace east --context ctx1"; strings.TrimSpace(string(args)) != expected {
+			t.Errorf("got: %s, expected: %s", strings.TrimSpace(string(args)), expected)
+		}
+	}
+
+	status, err := s.NetworkStatus()
+	if err != nil || strings.TrimSpace(status) != "network status --namespace east --context ctx1" {
+		t.Errorf("unexpected network status: %q (error: %v)", status, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.GatewayInitWithContext(ctx, skupper.GatewayInitFlags{Name: "sleep"}); err == nil || !strings.Contains(err.Error(), "did not complete") {
+		t.Errorf("expected timeout error, got: %v", err)
+	}
+}
@@ -2,18 +2,21 @@ package skupper
 
 import (
 	"context"
-	"os/exec"
 	"strconv"
 )
 
 type ConnectFlags struct {
 	ConnectionName string
-	Cost int
+	Cost           int
 }
 
 func (s *Skupper) Connect(tokenFile string, flags ConnectFlags) error {
+	return s.ConnectWithContext(context.TODO(), tokenFile, flags)
+}
 
-	cmdCtx := context.TODO()
+// ConnectWithContext connects the site to the one that generated the given token file,
+// waiting for the command to complete
+func (s *Skupper) ConnectWithContext(ctx context.Context, tokenFile string, flags ConnectFlags) error {
 
 	// Building args list
 	args := []string{
@@ -29,11 +32,6 @@ func (s *Skupper) Connect(tokenFile string, flags ConnectFlags) error {
 		args = append(args, "--cost", strconv.Itoa(flags.Cost))
 	}
 
-	// Global args
-	args = s.addGlobalArgs(args)
-
-	cmd := exec.CommandContext(cmdCtx, s.GetOperator().SkupperBin(), args...)
-	err := cmd.Start()
-
+	_, err := s.run(ctx, args...)
 	return err
 }
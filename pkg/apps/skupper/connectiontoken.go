@@ -2,7 +2,6 @@ package skupper
 
 import (
 	"context"
-	"os/exec"
 )
 
 type ConnectionTokenFlags struct {
@@ -10,8 +9,12 @@ type ConnectionTokenFlags struct {
 }
 
 func (s *Skupper) ConnectionToken(outputFile string, flags ConnectionTokenFlags) error {
+	return s.ConnectionTokenWithContext(context.TODO(), outputFile, flags)
+}
 
-	cmdCtx := context.TODO()
+// ConnectionTokenWithContext generates a connection token into the given output file,
+// waiting for the command to complete
+func (s *Skupper) ConnectionTokenWithContext(ctx context.Context, outputFile string, flags ConnectionTokenFlags) error {
 
 	// Building args list
 	args := []string{
@@ -24,17 +27,6 @@ func (s *Skupper) ConnectionToken(outputFile string, flags ConnectionTokenFlags)
 		args = append(args, "--client-identity", flags.ClientIdentity)
 	}
 
-	// Global args
-	args = s.addGlobalArgs(args)
-
-	// Run the command and wait till it finishes (generating the output file)
-	cmd := exec.CommandContext(cmdCtx, s.GetOperator().SkupperBin(), args...)
-	err := cmd.Start()
-	if err != nil {
-		return err
-	}
-
-	err = cmd.Wait()
-
+	_, err := s.run(ctx, args...)
 	return err
 }
@@ -2,34 +2,43 @@ package skupper
 
 import (
 	"context"
-	"os/exec"
 	"strconv"
 )
 
 type ExposeFlags struct {
-	Address string
-	Aggregate string
-	Headless bool
-	Port int
+	Address    string
+	Aggregate  string
+	Headless   bool
+	Port       int
 	TargetPort int
-	Protocol string
+	Protocol   string
 }
 
 func (s *Skupper) ExposeDeployment(name string, flags ExposeFlags) error {
-	return s.runExpose("deployment", name, flags)
+	return s.ExposeDeploymentWithContext(context.TODO(), name, flags)
+}
+
+func (s *Skupper) ExposeDeploymentWithContext(ctx context.Context, name string, flags ExposeFlags) error {
+	return s.runExpose(ctx, "deployment", name, flags)
 }
 
 func (s *Skupper) ExposePods(name string, flags ExposeFlags) error {
-	return s.runExpose("pods", name, flags)
+	return s.ExposePodsWithContext(context.TODO(), name, flags)
+}
+
+func (s *Skupper) ExposePodsWithContext(ctx context.Context, name string, flags ExposeFlags) error {
+	return s.runExpose(ctx, "pods", name, flags)
 }
 
 func (s *Skupper) ExposeStatefulset(name string, flags ExposeFlags) error {
-	return s.runExpose("statefulset", name, flags)
+	return s.ExposeStatefulsetWithContext(context.TODO(), name, flags)
 }
 
-func (s *Skupper) runExpose(resource string, name string, flags ExposeFlags) error {
+func (s *Skupper) ExposeStatefulsetWithContext(ctx context.Context, name string, flags ExposeFlags) error {
+	return s.runExpose(ctx, "statefulset", name, flags)
+}
 
-	cmdCtx := context.TODO()
+func (s *Skupper) runExpose(ctx context.Context, resource string, name string, flags ExposeFlags) error {
 
 	// Building args list
 	args := []string{
@@ -58,11 +67,33 @@ func (s *Skupper) runExpose(resource string, name string, flags ExposeFlags) err
 		args = append(args, "--protocol", flags.Protocol)
 	}
 
-	// Global args
-	args = s.addGlobalArgs(args)
+	_, err := s.run(ctx, args...)
+	return err
+}
+
+type UnexposeFlags struct {
+	Address string
+}
+
+func (s *Skupper) Unexpose(resource string, name string, flags UnexposeFlags) error {
+	return s.UnexposeWithContext(context.TODO(), resource, name, flags)
+}
+
+// UnexposeWithContext stops exposing the given resource (deployment, pods, statefulset or service)
+func (s *Skupper) UnexposeWithContext(ctx context.Context, resource string, name string, flags UnexposeFlags) error {
+
+	// Building args list
+	args := []string{
+		"unexpose",
+		resource,
+		name,
+	}
 
-	cmd := exec.CommandContext(cmdCtx, s.GetOperator().SkupperBin(), args...)
-	err := cmd.Start()
+	// Flags parsing
+	if flags.Address != "" {
+		args = append(args, "--address", flags.Address)
+	}
 
+	_, err := s.run(ctx, args...)
 	return err
 }
@@ -0,0 +1,93 @@
+package skupper
+
+import (
+	"context"
+	"strconv"
+)
+
+type GatewayInitFlags struct {
+	Name   string
+	Type   string
+	Config string
+}
+
+func (s *Skupper) GatewayInit(flags GatewayInitFlags) error {
+	return s.GatewayInitWithContext(context.TODO(), flags)
+}
+
+// GatewayInitWithContext initializes a gateway, linking the host running the
+// tests (as a service, docker or podman container) to the site
+func (s *Skupper) GatewayInitWithContext(ctx context.Context, flags GatewayInitFlags) error {
+
+	// Building args list
+	args := []string{
+		"gateway",
+		"init",
+	}
+
+	// Flags parsing
+	if flags.Name != "" {
+		args = append(args, "--name", flags.Name)
+	}
+	if flags.Type != "" {
+		args = append(args, "--type", flags.Type)
+	}
+	if flags.Config != "" {
+		args = append(args, "--config", flags.Config)
+	}
+
+	_, err := s.run(ctx, args...)
+	return err
+}
+
+type GatewayExposeFlags struct {
+	Protocol   string
+	Type       string
+	Aggregate  string
+	TargetPort int
+}
+
+func (s *Skupper) GatewayExpose(address string, host string, port int, flags GatewayExposeFlags) error {
+	return s.GatewayExposeWithContext(context.TODO(), address, host, port, flags)
+}
+
+// GatewayExposeWithContext exposes a process reachable from the gateway host
+// (at the given host and port) on the network, using the given address
+func (s *Skupper) GatewayExposeWithContext(ctx context.Context, address string, host string, port int, flags GatewayExposeFlags) error {
+
+	// Building args list
+	args := []string{
+		"gateway",
+		"expose",
+		address,
+		host,
+		strconv.Itoa(port),
+	}
+
+	// Flags parsing
+	if flags.Protocol != "" {
+		args = append(args, "--protocol", flags.Protocol)
+	}
+	if flags.Type != "" {
+		args = append(args, "--type", flags.Type)
+	}
+	if flags.Aggregate != "" {
+		args = append(args, "--aggregate", flags.Aggregate)
+	}
+	if flags.TargetPort > 0 {
+		args = append(args, "--target-port", strconv.Itoa(flags.TargetPort))
+	}
+
+	_, err := s.run(ctx, args...)
+	return err
+}
+
+func (s *Skupper) GatewayDelete() error {
+	return s.GatewayDeleteWithContext(context.TODO())
+}
+
+// GatewayDeleteWithContext stops and removes the gateway
+func (s *Skupper) GatewayDeleteWithContext(ctx context.Context) error {
+	_, err := s.run(ctx, "gateway", "delete")
+	return err
+}
@@ -0,0 +1,48 @@
+package skupper
+
+import (
+	"context"
+	"strconv"
+)
+
+type LinkCreateFlags struct {
+	Name string
+	Cost int
+}
+
+func (s *Skupper) LinkCreate(tokenFile string, flags LinkCreateFlags) error {
+	return s.LinkCreateWithContext(context.TODO(), tokenFile, flags)
+}
+
+// LinkCreateWithContext links the site to the one that generated the given token file
+// (replaces the "connect" command on newer skupper versions)
+func (s *Skupper) LinkCreateWithContext(ctx context.Context, tokenFile string, flags LinkCreateFlags) error {
+
+	// Building args list
+	args := []string{
+		"link",
+		"create",
+		tokenFile,
+	}
+
+	// Flags parsing
+	if flags.Name != "" {
+		args = append(args, "--name", flags.Name)
+	}
+	if flags.Cost > 0 {
+		args = append(args, "--cost", strconv.Itoa(flags.Cost))
+	}
+
+	_, err := s.run(ctx, args...)
+	return err
+}
+
+func (s *Skupper) LinkDelete(name string) error {
+	return s.LinkDeleteWithContext(context.TODO(), name)
+}
+
+// LinkDeleteWithContext deletes the link with the given name
+func (s *Skupper) LinkDeleteWithContext(ctx context.Context, name string) error {
+	_, err := s.run(ctx, "link", "delete", name)
+	return err
+}
@@ -0,0 +1,88 @@
+package skupper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var secretsGVR = corev1.SchemeGroupVersion.WithResource("secrets")
+
+// LinkSitesOptions customizes the link created by LinkSites
+type LinkSitesOptions struct {
+	// Name of the link (and of the token), defaults to "link-<target namespace>"
+	Name      string
+	TokenType string
+	Expiry    time.Duration
+	Cost      int
+	// Timeout for the link to become active, defaults to Timeout
+	Timeout time.Duration
+}
+
+func LinkSites(from, to *framework.ContextData, opts LinkSitesOptions) error {
+	return LinkSitesWithContext(context.TODO(), from, to, opts)
+}
+
+// LinkSitesWithContext links the site running on the from context to the site running on the
+// to context. The token is generated on the to context and applied on the from context as a
+// secret, without being written to local files, then the link is awaited to be active.
+// Both the link and the token are tracked by their contexts, to be removed when the owning
+// Framework runs AfterEach.
+func LinkSitesWithContext(ctx context.Context, from, to *framework.ContextData, opts LinkSitesOptions) error {
+	if opts.Name == "" {
+		opts.Name = "link-" + to.Namespace
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = Timeout
+	}
+
+	// Generating the token on the target site
+	token, err := NewSkupper(to).TokenCreateSecretWithContext(ctx, TokenCreateFlags{
+		Name:      opts.Name,
+		TokenType: opts.TokenType,
+		Expiry:    opts.Expiry,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to generate token on context %s: %v", to.Id, err)
+	}
+	if to.Tracker != nil {
+		to.Tracker.Track(secretsGVR, to.Namespace, opts.Name)
+	}
+
+	// Applying it on the source site, where it is turned into a link
+	link := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        opts.Name,
+			Namespace:   from.Namespace,
+			Labels:      token.Labels,
+			Annotations: token.Annotations,
+		},
+		Type: token.Type,
+		Data: token.Data,
+	}
+	if opts.Cost > 0 {
+		if link.Annotations == nil {
+			link.Annotations = map[string]string{}
+		}
+		link.Annotations["skupper.io/cost"] = strconv.Itoa(opts.Cost)
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(link)
+	if err != nil {
+		return fmt.Errorf("unable to convert link %s: %v", opts.Name, err)
+	}
+	if _, err := from.CreateResourceGroupVersionWithContext(ctx, secretsGVR, &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("unable to create link %s on context %s: %v", opts.Name, from.Id, err)
+	}
+	log.Logf("Linking site on context %s to site on context %s (%s)", from.Id, to.Id, opts.Name)
+
+	return NewSkupper(from).WaitForLinkActiveWithContext(ctx, opts.Name, opts.Timeout)
+}
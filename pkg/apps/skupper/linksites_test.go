@@ -0,0 +1,92 @@
+package skupper_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/rh-messaging/shipshape/pkg/apps/skupper"
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestLinkSites validates that the token generated on the target site is applied
+// as a link on the source site, and that both are removed on teardown
+func TestLinkSites(t *testing.T) {
+	ctx := context.Background()
+	f, err := framework.NewFrameworkBuilder("link").WithContexts("east", "west").WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+	east, west := f.ContextMap["east"], f.ContextMap["west"]
+
+	// The fake skupper binary prints a token or reports the link as active
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+  "token create"*) cat <<EOF
+apiVersion: v1
+data:
+  password: c2VjcmV0
+kind: Secret
+metadata:
+  annotations:
+    skupper.io/url: https://claims-west.example.com:443/1d1c0c4c
+  labels:
+    skupper.io/type: token-claim
+  name: east-to-west
+Token written to /dev/stdout (they will need permission to read it)
+EOF
+  ;;
+  "link status"*) echo "Link east-to-west is active" ;;
+esac
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "skupper"), []byte(script), 0755); err != nil {
+		t.Fatalf("unable to write fake skupper: %v", err)
+	}
+	for _, ctxData := range []*framework.ContextData{east, west} {
+		ctxData.OperatorMap[operators.OperatorTypeSkupper] = &operators.SkupperOperator{SkupperPath: dir + "/"}
+	}
+
+	// Simulating the token claim record, created by skupper on the target site
+	secrets := corev1.SchemeGroupVersion.WithResource("secrets")
+	claim := &unstructured.Unstructured{}
+	claim.SetAPIVersion("v1")
+	claim.SetKind("Secret")
+	claim.SetName("east-to-west")
+	if _, err := west.Clients.DynClient.Resource(secrets).Namespace(west.Namespace).Create(ctx, claim, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unable to create token claim: %v", err)
+	}
+
+	if err := skupper.LinkSitesWithContext(ctx, east, west, skupper.LinkSitesOptions{Name: "east-to-west", Cost: 5}); err != nil {
+		t.Fatalf("unexpected error linking sites: %v", err)
+	}
+	link, err := east.GetResourceGroupVersionWithContext(ctx, secrets, "east-to-west")
+	if err != nil {
+		t.Fatalf("link not created: %v", err)
+	}
+	if link.GetAnnotations()["skupper.io/cost"] != "5" || link.GetLabels()["skupper.io/type"] != "token-claim" {
+		t.Errorf("unexpected link: %v", link.Object)
+	}
+	if password, _, _ := unstructured.NestedString(link.Object, "data", "password"); password != "c2VjcmV0" {
+		t.Errorf("unexpected link password: %q", password)
+	}
+
+	// Namespaced resources are only removed when namespaces are not preserved
+	defer func(v bool) { framework.TestContext.DeleteNamespace = v }(framework.TestContext.DeleteNamespace)
+	framework.TestContext.DeleteNamespace = true
+	namespaces := map[*framework.ContextData]string{east: east.Namespace, west: west.Namespace}
+	if err := f.AfterEachWithContext(ctx); err != nil {
+		t.Errorf("unexpected error on teardown: %v", err)
+	}
+	for ctxData, namespace := range namespaces {
+		if _, err := ctxData.Clients.DynClient.Resource(secrets).Namespace(namespace).Get(ctx, "east-to-west", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			t.Errorf("secret not removed from context %s: %v", ctxData.Id, err)
+		}
+	}
+}
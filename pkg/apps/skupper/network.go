@@ -0,0 +1,19 @@
+package skupper
+
+import (
+	"context"
+)
+
+func (s *Skupper) NetworkStatus() (string, error) {
+	return s.NetworkStatusWithContext(context.TODO())
+}
+
+// NetworkStatusWithContext returns the output of "skupper network status",
+// describing all sites of the network along with the services they expose
+func (s *Skupper) NetworkStatusWithContext(ctx context.Context) (string, error) {
+	result, err := s.run(ctx, "network", "status")
+	if err != nil {
+		return "", err
+	}
+	return result.Stdout, nil
+}
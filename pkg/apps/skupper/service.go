@@ -0,0 +1,100 @@
+package skupper
+
+import (
+	"context"
+	"strconv"
+)
+
+type ServiceCreateFlags struct {
+	Protocol     string
+	Aggregate    string
+	EventChannel bool
+	EnableTLS    bool
+}
+
+func (s *Skupper) ServiceCreate(name string, port int, flags ServiceCreateFlags) error {
+	return s.ServiceCreateWithContext(context.TODO(), name, port, flags)
+}
+
+// ServiceCreateWithContext creates a service on the network, not bound to any target yet
+func (s *Skupper) ServiceCreateWithContext(ctx context.Context, name string, port int, flags ServiceCreateFlags) error {
+
+	// Building args list
+	args := []string{
+		"service",
+		"create",
+		name,
+		strconv.Itoa(port),
+	}
+
+	// Flags parsing
+	if flags.Protocol != "" {
+		args = append(args, "--protocol", flags.Protocol)
+	}
+	if flags.Aggregate != "" {
+		args = append(args, "--aggregate", flags.Aggregate)
+	}
+	if flags.EventChannel {
+		args = append(args, "--event-channel")
+	}
+	if flags.EnableTLS {
+		args = append(args, "--enable-tls")
+	}
+
+	_, err := s.run(ctx, args...)
+	return err
+}
+
+func (s *Skupper) ServiceDelete(name string) error {
+	return s.ServiceDeleteWithContext(context.TODO(), name)
+}
+
+// ServiceDeleteWithContext deletes the given service from the network
+func (s *Skupper) ServiceDeleteWithContext(ctx context.Context, name string) error {
+	_, err := s.run(ctx, "service", "delete", name)
+	return err
+}
+
+type ServiceBindFlags struct {
+	Protocol   string
+	TargetPort int
+}
+
+func (s *Skupper) ServiceBind(name string, targetType string, targetName string, flags ServiceBindFlags) error {
+	return s.ServiceBindWithContext(context.TODO(), name, targetType, targetName, flags)
+}
+
+// ServiceBindWithContext binds the given service to a target (deployment, pods, statefulset or service)
+// from the context's namespace
+func (s *Skupper) ServiceBindWithContext(ctx context.Context, name string, targetType string, targetName string, flags ServiceBindFlags) error {
+
+	// Building args list
+	args := []string{
+		"service",
+		"bind",
+		name,
+		targetType,
+		targetName,
+	}
+
+	// Flags parsing
+	if flags.Protocol != "" {
+		args = append(args, "--protocol", flags.Protocol)
+	}
+	if flags.TargetPort > 0 {
+		args = append(args, "--target-port", strconv.Itoa(flags.TargetPort))
+	}
+
+	_, err := s.run(ctx, args...)
+	return err
+}
+
+func (s *Skupper) ServiceUnbind(name string, targetType string, targetName string) error {
+	return s.ServiceUnbindWithContext(context.TODO(), name, targetType, targetName)
+}
+
+// ServiceUnbindWithContext removes the given target from the service
+func (s *Skupper) ServiceUnbindWithContext(ctx context.Context, name string, targetType string, targetName string) error {
+	_, err := s.run(ctx, "service", "unbind", name, targetType, targetName)
+	return err
+}
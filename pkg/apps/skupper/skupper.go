@@ -1,6 +1,8 @@
 package skupper
 
 import (
+	"context"
+
 	"github.com/rh-messaging/shipshape/pkg/framework"
 	"github.com/rh-messaging/shipshape/pkg/framework/operators"
 )
@@ -24,3 +26,8 @@ func (s *Skupper) addGlobalArgs(args []string) []string {
 	args = append(args, "--context", s.ctx.Id)
 	return args
 }
+
+// run runs the skupper CLI against the context's namespace, waiting for it to complete
+func (s *Skupper) run(ctx context.Context, args ...string) (*operators.SkupperResult, error) {
+	return s.GetOperator().RunWithContext(ctx, s.addGlobalArgs(args)...)
+}
@@ -0,0 +1,228 @@
+package skupper
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	RetryInterval = 5 * time.Second
+	Timeout       = 3 * time.Minute
+
+	siteEnabledRegexp   = regexp.MustCompile(`Skupper is enabled for namespace "([^"]*)"(?: with site name "([^"]*)")? in (\S+) mode`)
+	connectedRegexp     = regexp.MustCompile(`connected to (\d+) other sites?(?: \((\d+) indirectly\))?`)
+	exposedRegexp       = regexp.MustCompile(`has (\d+) exposed services?`)
+	linkRegexp          = regexp.MustCompile(`^Link (\S+) (?:is )?(active|not active|not connected|pending)`)
+	serviceRegexp       = regexp.MustCompile(`^(\S+) \((\S+) port (\d+)\)`)
+	serviceTargetRegexp = regexp.MustCompile(`^\S+=\S+`)
+)
+
+// SiteStatus holds the information reported by "skupper status"
+type SiteStatus struct {
+	Enabled         bool
+	Namespace       string
+	SiteName        string
+	Mode            string
+	ConnectedSites  int
+	IndirectSites   int
+	ExposedServices int
+}
+
+// LinkStatus holds the status of a link created from the site, as reported by "skupper link status"
+type LinkStatus struct {
+	Name   string
+	Active bool
+}
+
+// ServiceStatus holds a service exposed through the network, as reported by "skupper service status"
+type ServiceStatus struct {
+	Name     string
+	Protocol string
+	Port     int
+	Targets  []string
+}
+
+func (s *Skupper) Status() (*SiteStatus, error) {
+	return s.StatusWithContext(context.TODO())
+}
+
+// StatusWithContext returns the status of the site
+func (s *Skupper) StatusWithContext(ctx context.Context) (*SiteStatus, error) {
+	result, err := s.run(ctx, "status")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSiteStatus(result.Stdout), nil
+}
+
+func (s *Skupper) LinkStatus() ([]LinkStatus, error) {
+	return s.LinkStatusWithContext(context.TODO())
+}
+
+// LinkStatusWithContext returns the status of the links created from the site
+func (s *Skupper) LinkStatusWithContext(ctx context.Context) ([]LinkStatus, error) {
+	result, err := s.run(ctx, "link", "status")
+	if err != nil {
+		return nil, err
+	}
+	return ParseLinkStatus(result.Stdout), nil
+}
+
+func (s *Skupper) ServiceStatus() ([]ServiceStatus, error) {
+	return s.ServiceStatusWithContext(context.TODO())
+}
+
+// ServiceStatusWithContext returns the services exposed through the network, as seen by the site
+func (s *Skupper) ServiceStatusWithContext(ctx context.Context) ([]ServiceStatus, error) {
+	result, err := s.run(ctx, "service", "status")
+	if err != nil {
+		return nil, err
+	}
+	return ParseServiceStatus(result.Stdout), nil
+}
+
+// ParseSiteStatus parses the output of "skupper status"
+func ParseSiteStatus(output string) *SiteStatus {
+	status := &SiteStatus{}
+	match := siteEnabledRegexp.FindStringSubmatch(output)
+	if match == nil {
+		return status
+	}
+	status.Enabled = true
+	status.Namespace, status.SiteName, status.Mode = match[1], match[2], match[3]
+	if match = connectedRegexp.FindStringSubmatch(output); match != nil {
+		status.ConnectedSites, _ = strconv.Atoi(match[1])
+		status.IndirectSites, _ = strconv.Atoi(match[2])
+	}
+	if match = exposedRegexp.FindStringSubmatch(output); match != nil {
+		status.ExposedServices, _ = strconv.Atoi(match[1])
+	}
+	return status
+}
+
+// ParseLinkStatus parses the output of "skupper link status"
+func ParseLinkStatus(output string) []LinkStatus {
+	var links []LinkStatus
+	for _, line := range strings.Split(output, "\n") {
+		if match := linkRegexp.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			links = append(links, LinkStatus{Name: match[1], Active: match[2] == "active"})
+		}
+	}
+	return links
+}
+
+// ParseServiceStatus parses the output of "skupper service status"
+func ParseServiceStatus(output string) []ServiceStatus {
+	var services []ServiceStatus
+	for _, line := range strings.Split(output, "\n") {
+		// Skipping the tree drawing characters
+		line = strings.TrimSpace(strings.TrimLeftFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}))
+		if match := serviceRegexp.FindStringSubmatch(line); match != nil {
+			port, _ := strconv.Atoi(match[3])
+			services = append(services, ServiceStatus{Name: match[1], Protocol: match[2], Port: port})
+		} else if serviceTargetRegexp.MatchString(line) && len(services) > 0 {
+			last := &services[len(services)-1]
+			last.Targets = append(last.Targets, line)
+		}
+	}
+	return services
+}
+
+// waitFor polls the given condition, returning an error that includes the given
+// description and the last reported state when it is not met in time
+func (s *Skupper) waitFor(ctx context.Context, description string, timeout time.Duration, condition func(ctx context.Context) (bool, string, error)) error {
+	var state string
+	var lastErr error
+	err := wait.PollImmediateWithContext(ctx, RetryInterval, timeout, func(ctx context.Context) (bool, error) {
+		done, current, err := condition(ctx)
+		if err != nil {
+			lastErr = err
+			log.Logf("[%s] Waiting for %s: %v", s.ctx.Id, description, err)
+			return false, nil
+		}
+		state = current
+		return done, nil
+	})
+	if err != nil {
+		if lastErr != nil {
+			return fmt.Errorf("context %s: timed out waiting for %s (%s): %v (last error: %v)", s.ctx.Id, description, state, err, lastErr)
+		}
+		return fmt.Errorf("context %s: timed out waiting for %s (%s): %v", s.ctx.Id, description, state, err)
+	}
+	return nil
+}
+
+func (s *Skupper) WaitForConnectedSites(count int) error {
+	return s.WaitForConnectedSitesWithContext(context.TODO(), count, Timeout)
+}
+
+// WaitForConnectedSitesWithContext waits until the site reports being connected
+// to the given number of other sites (directly or indirectly)
+func (s *Skupper) WaitForConnectedSitesWithContext(ctx context.Context, count int, timeout time.Duration) error {
+	return s.waitFor(ctx, fmt.Sprintf("%d connected sites", count), timeout, func(ctx context.Context) (bool, string, error) {
+		status, err := s.StatusWithContext(ctx)
+		if err != nil {
+			return false, "", err
+		}
+		return status.Enabled && status.ConnectedSites == count, fmt.Sprintf("connected to %d sites", status.ConnectedSites), nil
+	})
+}
+
+func (s *Skupper) WaitForLinkActive(name string) error {
+	return s.WaitForLinkActiveWithContext(context.TODO(), name, Timeout)
+}
+
+// WaitForLinkActiveWithContext waits until the link with the given name is active
+func (s *Skupper) WaitForLinkActiveWithContext(ctx context.Context, name string, timeout time.Duration) error {
+	return s.waitFor(ctx, fmt.Sprintf("link %s to be active", name), timeout, func(ctx context.Context) (bool, string, error) {
+		links, err := s.LinkStatusWithContext(ctx)
+		if err != nil {
+			return false, "", err
+		}
+		for _, link := range links {
+			if link.Name == name {
+				return link.Active, fmt.Sprintf("link %s active: %t", name, link.Active), nil
+			}
+		}
+		return false, fmt.Sprintf("link %s not found", name), nil
+	})
+}
+
+func (s *Skupper) WaitForService(name string) error {
+	return s.WaitForServiceWithContext(context.TODO(), name, Timeout)
+}
+
+// WaitForServiceWithContext waits until the given service, exposed by any site of the network,
+// is known to this site and available as a kubernetes service in the context's namespace
+func (s *Skupper) WaitForServiceWithContext(ctx context.Context, name string, timeout time.Duration) error {
+	return s.waitFor(ctx, fmt.Sprintf("service %s", name), timeout, func(ctx context.Context) (bool, string, error) {
+		services, err := s.ServiceStatusWithContext(ctx)
+		if err != nil {
+			return false, "", err
+		}
+		found := false
+		for _, service := range services {
+			found = found || service.Name == name
+		}
+		if !found {
+			return false, "not exposed to the site", nil
+		}
+		_, err = s.ctx.Clients.KubeClient.CoreV1().Services(s.ctx.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, "kubernetes service not created", nil
+		}
+		return err == nil, "available", err
+	})
+}
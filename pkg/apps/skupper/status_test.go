@@ -0,0 +1,58 @@
+package skupper_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rh-messaging/shipshape/pkg/apps/skupper"
+)
+
+func TestParseSiteStatus(t *testing.T) {
+	output := `Skupper is enabled for namespace "east" with site name "east" in interior mode. It is connected to 2 other sites (1 indirectly). It has 3 exposed services.
+The site console url is:  https://skupper-east.apps.example.com`
+	expected := &skupper.SiteStatus{Enabled: true, Namespace: "east", SiteName: "east", Mode: "interior",
+		ConnectedSites: 2, IndirectSites: 1, ExposedServices: 3}
+	if status := skupper.ParseSiteStatus(output); !reflect.DeepEqual(status, expected) {
+		t.Errorf("got: %+v, expected: %+v", status, expected)
+	}
+
+	output = `Skupper is enabled for namespace "west" in edge mode. It is not connected to any other sites. It has no exposed services.`
+	expected = &skupper.SiteStatus{Enabled: true, Namespace: "west", Mode: "edge"}
+	if status := skupper.ParseSiteStatus(output); !reflect.DeepEqual(status, expected) {
+		t.Errorf("got: %+v, expected: %+v", status, expected)
+	}
+
+	if status := skupper.ParseSiteStatus(`Skupper is not enabled in namespace 'west'`); status.Enabled {
+		t.Errorf("expected site not enabled, got: %+v", status)
+	}
+}
+
+func TestParseLinkStatus(t *testing.T) {
+	output := `Links created from this site:
+-------------------------------
+Link link1 is active
+Link link2 not active
+
+Currently active links from other sites:
+----------------------------------------
+A link from the namespace west on site west(6b3d) is active`
+	expected := []skupper.LinkStatus{{Name: "link1", Active: true}, {Name: "link2", Active: false}}
+	if links := skupper.ParseLinkStatus(output); !reflect.DeepEqual(links, expected) {
+		t.Errorf("got: %+v, expected: %+v", links, expected)
+	}
+}
+
+func TestParseServiceStatus(t *testing.T) {
+	output := `Services exposed through Skupper:
+├─ backend (tcp port 8080)
+│  ╰─ Targets:
+│     ╰─ app=backend name=backend
+╰─ frontend (http port 80)`
+	expected := []skupper.ServiceStatus{
+		{Name: "backend", Protocol: "tcp", Port: 8080, Targets: []string{"app=backend name=backend"}},
+		{Name: "frontend", Protocol: "http", Port: 80},
+	}
+	if services := skupper.ParseServiceStatus(output); !reflect.DeepEqual(services, expected) {
+		t.Errorf("got: %+v, expected: %+v", services, expected)
+	}
+}
@@ -0,0 +1,95 @@
+package skupper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Token types
+const (
+	TokenTypeClaim = "claim"
+	TokenTypeCert  = "cert"
+)
+
+type TokenCreateFlags struct {
+	Name      string
+	TokenType string
+	Expiry    time.Duration
+	Uses      int
+	Password  string
+}
+
+func (s *Skupper) TokenCreate(outputFile string, flags TokenCreateFlags) error {
+	return s.TokenCreateWithContext(context.TODO(), outputFile, flags)
+}
+
+// TokenCreateWithContext generates a token into the given output file, to be used by
+// other sites to link to this one (replaces the "connection-token" command on newer skupper versions)
+func (s *Skupper) TokenCreateWithContext(ctx context.Context, outputFile string, flags TokenCreateFlags) error {
+	_, err := s.run(ctx, tokenCreateArgs(outputFile, flags)...)
+	return err
+}
+
+func (s *Skupper) TokenCreateSecret(flags TokenCreateFlags) (*corev1.Secret, error) {
+	return s.TokenCreateSecretWithContext(context.TODO(), flags)
+}
+
+// TokenCreateSecretWithContext generates a token, returning the secret it is made of
+// (instead of writing it to a file), so it can be applied to other sites directly
+func (s *Skupper) TokenCreateSecretWithContext(ctx context.Context, flags TokenCreateFlags) (*corev1.Secret, error) {
+	result, err := s.run(ctx, tokenCreateArgs("/dev/stdout", flags)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseTokenSecret(result.Stdout)
+}
+
+func tokenCreateArgs(outputFile string, flags TokenCreateFlags) []string {
+
+	// Building args list
+	args := []string{
+		"token",
+		"create",
+		outputFile,
+	}
+
+	// Flags parsing
+	if flags.Name != "" {
+		args = append(args, "--name", flags.Name)
+	}
+	if flags.TokenType != "" {
+		args = append(args, "--token-type", flags.TokenType)
+	}
+	if flags.Expiry > 0 {
+		args = append(args, "--expiry", flags.Expiry.String())
+	}
+	if flags.Uses > 0 {
+		args = append(args, "--uses", strconv.Itoa(flags.Uses))
+	}
+	if flags.Password != "" {
+		args = append(args, "--password", flags.Password)
+	}
+	return args
+}
+
+// parseTokenSecret parses the token secret written to the standard output,
+// ignoring the messages printed by skupper after it
+func parseTokenSecret(output string) (*corev1.Secret, error) {
+	if i := strings.Index(output, "\nToken written to"); i >= 0 {
+		output = output[:i]
+	}
+	secret := &corev1.Secret{}
+	if err := yaml.Unmarshal([]byte(output), secret); err != nil {
+		return nil, fmt.Errorf("unable to parse token: %v", err)
+	}
+	if secret.Kind != "Secret" || len(secret.Data) == 0 {
+		return nil, fmt.Errorf("unexpected token: %s", output)
+	}
+	return secret, nil
+}
@@ -0,0 +1,31 @@
+package skupper
+
+import (
+	"testing"
+)
+
+func TestParseTokenSecret(t *testing.T) {
+	output := `apiVersion: v1
+data:
+  password: c2VjcmV0
+kind: Secret
+metadata:
+  annotations:
+    skupper.io/url: https://claims-east.example.com:443/1d1c0c4c
+  labels:
+    skupper.io/type: token-claim
+  name: link-east
+Token written to /dev/stdout (they will need permission to read it)
+`
+	secret, err := parseTokenSecret(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secret.Name != "link-east" || string(secret.Data["password"]) != "secret" || secret.Labels["skupper.io/type"] != "token-claim" {
+		t.Errorf("unexpected secret: %+v", secret)
+	}
+
+	if _, err := parseTokenSecret("Error: Skupper is not enabled"); err == nil {
+		t.Errorf("expected error parsing invalid token")
+	}
+}
@@ -22,5 +22,9 @@ import (
 )
 
 func (c *ContextData) GetSecret(name string) (*corev1.Secret, error) {
-	return c.Clients.KubeClient.CoreV1().Secrets(c.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return c.GetSecretWithContext(context.TODO(), name)
+}
+
+func (c *ContextData) GetSecretWithContext(ctx context.Context, name string) (*corev1.Secret, error) {
+	return c.Clients.KubeClient.CoreV1().Secrets(c.Namespace).Get(ctx, name, metav1.GetOptions{})
 }
@@ -16,7 +16,12 @@ limitations under the License.
 
 package framework
 
-import "sync"
+import (
+	"context"
+	"sync"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+)
 
 // CleanupActionHandle is an integer pointer type for handling cleanup action
 type CleanupActionHandle *int
@@ -83,3 +88,14 @@ func RunCleanupActions(action ActionType) {
 		fn()
 	}
 }
+
+// AddCleanup registers a function to be called when the Framework that owns
+// this context runs AfterEach, before the tracked resources and the operators
+// are removed. Functions run in reverse order of registration.
+func (c *ContextData) AddCleanup(fn func(ctx context.Context) error) {
+	if c.Tracker == nil {
+		log.Logf("Context %s has no tracker, cleanup function will not be called", c.Id)
+		return
+	}
+	c.Tracker.AddCleanup(fn)
+}
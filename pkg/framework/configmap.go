@@ -18,6 +18,10 @@ type ConfigMapData struct {
 
 // CreateConfigMapData helper method to generate a ConfiMap using configuration Data
 func (c *ContextData) CreateConfigMapData(name string, data ...ConfigMapData) (*v1.ConfigMap, error) {
+	return c.CreateConfigMapDataWithContext(context.TODO(), name, data...)
+}
+
+func (c *ContextData) CreateConfigMapDataWithContext(ctx context.Context, name string, data ...ConfigMapData) (*v1.ConfigMap, error) {
 	// In case no data element provided
 	if data == nil || len(data) == 0 {
 		return nil, fmt.Errorf("need at least one ConfigDataMap element")
@@ -27,12 +31,15 @@ func (c *ContextData) CreateConfigMapData(name string, data ...ConfigMapData) (*
 		dataMap[d.Name] = d.Data
 	}
 
-	cfgMap, err := c.Clients.KubeClient.CoreV1().ConfigMaps(c.Namespace).Create(context.TODO(), &v1.ConfigMap{
+	cfgMap, err := c.Clients.KubeClient.CoreV1().ConfigMaps(c.Namespace).Create(ctx, &v1.ConfigMap{
 		ObjectMeta: v12.ObjectMeta{
 			Name: name,
 		},
 		Data: dataMap,
 	}, metav1.CreateOptions{})
+	if err == nil {
+		c.track(v1.SchemeGroupVersion.WithResource("configmaps"), cfgMap.Namespace, cfgMap.Name)
+	}
 
 	return cfgMap, err
 }
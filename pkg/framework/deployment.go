@@ -16,6 +16,7 @@ package framework
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/onsi/gomega"
@@ -31,7 +32,11 @@ import (
 )
 
 func (c *ContextData) GetDeployment(name string) (*appsv1.Deployment, error) {
-	return c.Clients.KubeClient.AppsV1().Deployments(c.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return c.GetDeploymentWithContext(context.TODO(), name)
+}
+
+func (c *ContextData) GetDeploymentWithContext(ctx context.Context, name string) (*appsv1.Deployment, error) {
+	return c.Clients.KubeClient.AppsV1().Deployments(c.Namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
 func (c *ContextData) ListPodsForDeploymentName(name string) (*corev1.PodList, error) {
@@ -44,13 +49,27 @@ func (c *ContextData) ListPodsForDeploymentName(name string) (*corev1.PodList, e
 	return c.ListPodsForDeployment(deployment)
 }
 
+// ListPodsForDeploymentNameWithContext returns the pods for the given deployment name,
+// returning an error (instead of failing the spec) if the deployment cannot be retrieved
+func (c *ContextData) ListPodsForDeploymentNameWithContext(ctx context.Context, name string) (*corev1.PodList, error) {
+	deployment, err := c.GetDeploymentWithContext(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve deployment %s: %v", name, err)
+	}
+	return c.ListPodsForDeploymentWithContext(ctx, deployment)
+}
+
 func (c *ContextData) ListPodsForDeployment(deployment *appsv1.Deployment) (*corev1.PodList, error) {
+	return c.ListPodsForDeploymentWithContext(context.TODO(), deployment)
+}
+
+func (c *ContextData) ListPodsForDeploymentWithContext(ctx context.Context, deployment *appsv1.Deployment) (*corev1.PodList, error) {
 	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
 	if err != nil {
 		return nil, err
 	}
 	listOps := metav1.ListOptions{LabelSelector: selector.String()}
-	return c.Clients.KubeClient.CoreV1().Pods(c.Namespace).List(context.TODO(), listOps)
+	return c.Clients.KubeClient.CoreV1().Pods(c.Namespace).List(ctx, listOps)
 }
 
 func WaitForStatefulSet(kubeclient kubernetes.Interface, namespace, name string, count int, retryInterval, timeout time.Duration) error { // I'd deprecate this method but it might be used in tests etc.
@@ -58,8 +77,12 @@ func WaitForStatefulSet(kubeclient kubernetes.Interface, namespace, name string,
 }
 
 func WaitForStatefulSetReady(kubeclient kubernetes.Interface, namespace, name string, count int, retryInterval, timeout time.Duration) error {
-	err := wait.Poll(retryInterval, timeout, func() (done bool, err error) {
-		ds, err := kubeclient.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return WaitForStatefulSetReadyWithContext(context.Background(), kubeclient, namespace, name, count, retryInterval, timeout)
+}
+
+func WaitForStatefulSetReadyWithContext(ctx context.Context, kubeclient kubernetes.Interface, namespace, name string, count int, retryInterval, timeout time.Duration) error {
+	err := wait.PollWithContext(ctx, retryInterval, timeout, func(ctx context.Context) (done bool, err error) {
+		ds, err := kubeclient.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				log.Logf("Waiting for availability of %s stateful set", name)
@@ -82,8 +105,12 @@ func WaitForStatefulSetReady(kubeclient kubernetes.Interface, namespace, name st
 }
 
 func WaitForStatefulSetCreation(kubeclient kubernetes.Interface, namespace, name string, retryInterval, timeout time.Duration) error {
-	err := wait.Poll(retryInterval, timeout, func() (done bool, err error) {
-		_, err = kubeclient.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return WaitForStatefulSetCreationWithContext(context.Background(), kubeclient, namespace, name, retryInterval, timeout)
+}
+
+func WaitForStatefulSetCreationWithContext(ctx context.Context, kubeclient kubernetes.Interface, namespace, name string, retryInterval, timeout time.Duration) error {
+	err := wait.PollWithContext(ctx, retryInterval, timeout, func(ctx context.Context) (done bool, err error) {
+		_, err = kubeclient.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				log.Logf("Waiting for availability of %s stateful set", name)
@@ -102,8 +129,12 @@ func WaitForStatefulSetCreation(kubeclient kubernetes.Interface, namespace, name
 }
 
 func WaitForDeployment(kubeclient kubernetes.Interface, namespace, name string, replicas int, retryInterval, timeout time.Duration) error {
-	err := wait.Poll(retryInterval, timeout, func() (done bool, err error) {
-		deployment, err := kubeclient.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return WaitForDeploymentWithContext(context.Background(), kubeclient, namespace, name, replicas, retryInterval, timeout)
+}
+
+func WaitForDeploymentWithContext(ctx context.Context, kubeclient kubernetes.Interface, namespace, name string, replicas int, retryInterval, timeout time.Duration) error {
+	err := wait.PollWithContext(ctx, retryInterval, timeout, func(ctx context.Context) (done bool, err error) {
+		deployment, err := kubeclient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				log.Logf("Waiting for availability of %s deployment in %s namepsace", name, namespace)
@@ -140,12 +171,20 @@ func WaitForDeployment(kubeclient kubernetes.Interface, namespace, name string,
 }
 
 func (c *ContextData) GetDaemonSet(name string) (*appsv1.DaemonSet, error) {
-	return c.Clients.KubeClient.AppsV1().DaemonSets(c.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return c.GetDaemonSetWithContext(context.TODO(), name)
+}
+
+func (c *ContextData) GetDaemonSetWithContext(ctx context.Context, name string) (*appsv1.DaemonSet, error) {
+	return c.Clients.KubeClient.AppsV1().DaemonSets(c.Namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
 func WaitForDaemonSet(kubeclient kubernetes.Interface, namespace, name string, count int, retryInterval, timeout time.Duration) error {
-	err := wait.Poll(retryInterval, timeout, func() (done bool, err error) {
-		ds, err := kubeclient.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return WaitForDaemonSetWithContext(context.Background(), kubeclient, namespace, name, count, retryInterval, timeout)
+}
+
+func WaitForDaemonSetWithContext(ctx context.Context, kubeclient kubernetes.Interface, namespace, name string, count int, retryInterval, timeout time.Duration) error {
+	err := wait.PollWithContext(ctx, retryInterval, timeout, func(ctx context.Context) (done bool, err error) {
+		ds, err := kubeclient.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				log.Logf("Waiting for availability of %s daemon set", name)
@@ -168,12 +207,14 @@ func WaitForDaemonSet(kubeclient kubernetes.Interface, namespace, name string, c
 }
 
 func WaitForDeletion(dynclient client.Client, obj client.Object, retryInterval, timeout time.Duration) error {
+	return WaitForDeletionWithContext(context.Background(), dynclient, obj, retryInterval, timeout)
+}
+
+func WaitForDeletionWithContext(ctx context.Context, dynclient client.Client, obj client.Object, retryInterval, timeout time.Duration) error {
 	key := client.ObjectKeyFromObject(obj)
 
 	kind := obj.GetObjectKind().GroupVersionKind().Kind
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-	err := wait.Poll(retryInterval, timeout, func() (done bool, err error) {
+	err := wait.PollWithContext(ctx, retryInterval, timeout, func(ctx context.Context) (done bool, err error) {
 		err = dynclient.Get(ctx, key, obj)
 		if apierrors.IsNotFound(err) {
 			return true, nil
@@ -193,7 +234,7 @@ func WaitForDeletion(dynclient client.Client, obj client.Object, retryInterval,
 
 func WaitForDeploymentDeleted(ctx context.Context, kubeclient kubernetes.Interface, namespace, name string) error {
 	err := RetryWithContext(ctx, RetryInterval, func() (bool, error) {
-		deployment, err := kubeclient.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		deployment, err := kubeclient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				return true, nil
@@ -0,0 +1,290 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/onsi/ginkgo"
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+var (
+	unsafePathChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// operatorInfo is the serializable representation of an OperatorSetup
+type operatorInfo struct {
+	Type       string   `json:"type"`
+	Name       string   `json:"name"`
+	Namespace  string   `json:"namespace"`
+	Image      string   `json:"image"`
+	APIVersion string   `json:"apiVersion,omitempty"`
+	GroupName  string   `json:"groupName,omitempty"`
+	CRDNames   []string `json:"crdNames,omitempty"`
+}
+
+// diagnosticsBundleName returns a directory name for the diagnostics
+// bundle, based on the running spec (if any) and the current time
+func diagnosticsBundleName() string {
+	name := "diagnostics"
+	if spec := ginkgo.CurrentGinkgoTestDescription(); spec.FullTestText != "" {
+		name = spec.FullTestText
+	}
+	return fmt.Sprintf("%s-%s", safePathName(name), time.Now().Format("20060102-150405"))
+}
+
+// safePathName replaces all characters that are not safe to be used as a
+// file or directory name
+func safePathName(name string) string {
+	return unsafePathChars.ReplaceAllString(name, "_")
+}
+
+// CollectDiagnostics dumps a diagnostics bundle for each context into the given
+// directory, organized as <dir>/<context>/<namespace>. Collection is done on a best
+// effort basis, so all errors found are aggregated into the returned error.
+func (f *Framework) CollectDiagnostics(ctx context.Context, dir string) error {
+	var errs []error
+	for id, ctxData := range f.ContextMap {
+		if err := ctxData.CollectDiagnosticsWithContext(ctx, filepath.Join(dir, safePathName(id))); err != nil {
+			errs = append(errs, fmt.Errorf("context %s: %v", id, err))
+		}
+	}
+	log.Logf("Diagnostics bundle saved to %s", dir)
+	return utilerrors.NewAggregate(errs)
+}
+
+// collectDiagnosticsOnFailure dumps the diagnostics bundle under TestContext.OutputDir
+// in case the running spec has failed
+func (f *Framework) collectDiagnosticsOnFailure(ctx context.Context) {
+	if TestContext.OutputDir == "" || !ginkgo.CurrentGinkgoTestDescription().Failed {
+		return
+	}
+	dir := filepath.Join(TestContext.OutputDir, diagnosticsBundleName())
+	if err := f.CollectDiagnostics(ctx, dir); err != nil {
+		log.Logf("Errors collecting diagnostics bundle: %v", err)
+	}
+}
+
+// CollectDiagnosticsWithContext dumps pod logs, the YAML representation of the main
+// workload resources and custom resources, events and the operators configuration
+// for all namespaces owned by this context into <dir>/<namespace>
+func (c *ContextData) CollectDiagnosticsWithContext(ctx context.Context, dir string) error {
+	var errs []error
+	for _, namespace := range c.namespaces() {
+		nsDir := filepath.Join(dir, safePathName(namespace))
+		if err := os.MkdirAll(nsDir, 0755); err != nil {
+			return err
+		}
+		errs = append(errs, c.collectNamespaceDiagnostics(ctx, namespace, nsDir)...)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	errs = append(errs, c.collectOperatorDiagnostics(ctx, dir)...)
+	return utilerrors.NewAggregate(errs)
+}
+
+// namespaces returns the (unique) namespaces owned by this context
+func (c *ContextData) namespaces() []string {
+	names := map[string]bool{}
+	if c.Namespace != "" {
+		names[c.Namespace] = true
+	}
+	for _, ns := range c.namespacesToDelete {
+		names[ns.Name] = true
+	}
+	for _, project := range c.projectsToDelete {
+		names[project.Name] = true
+	}
+	var result []string
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (c *ContextData) collectNamespaceDiagnostics(ctx context.Context, namespace, dir string) []error {
+	var errs []error
+	collect := func(file string, list func() (interface{}, error)) {
+		obj, err := list()
+		if err == nil {
+			err = writeYaml(filepath.Join(dir, file), obj)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to collect %s from %s: %v", file, namespace, err))
+		}
+	}
+
+	kube := c.Clients.KubeClient
+	collect("deployments.yaml", func() (interface{}, error) {
+		return kube.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	})
+	collect("statefulsets.yaml", func() (interface{}, error) {
+		return kube.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	})
+	collect("services.yaml", func() (interface{}, error) {
+		return kube.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	})
+	collect("events.yaml", func() (interface{}, error) {
+		events, err := kube.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+		if err == nil {
+			sort.SliceStable(events.Items, func(i, j int) bool {
+				return events.Items[i].LastTimestamp.Before(&events.Items[j].LastTimestamp)
+			})
+		}
+		return events, err
+	})
+
+	pods, err := kube.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return append(errs, fmt.Errorf("unable to list pods from %s: %v", namespace, err))
+	}
+	collect("pods.yaml", func() (interface{}, error) {
+		return pods, nil
+	})
+	for _, pod := range pods.Items {
+		errs = append(errs, c.collectPodLogs(ctx, pod, filepath.Join(dir, "logs"))...)
+	}
+
+	errs = append(errs, c.collectCustomResources(ctx, namespace, filepath.Join(dir, "crs"))...)
+	return errs
+}
+
+// collectPodLogs saves the logs of all containers from the given pod, including
+// the logs from the previous instance of restarted containers
+func (c *ContextData) collectPodLogs(ctx context.Context, pod corev1.Pod, dir string) []error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	save := func(container string, previous bool) {
+		suffix := ".log"
+		if previous {
+			suffix = ".previous.log"
+		}
+		file := filepath.Join(dir, safePathName(pod.Name+"-"+container)+suffix)
+		request := c.Clients.KubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: container,
+			Previous:  previous,
+		})
+		logs, err := request.DoRaw(ctx)
+		if err == nil {
+			err = ioutil.WriteFile(file, logs, 0644)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to collect logs from %s/%s: %v", pod.Name, container, err))
+		}
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		save(status.Name, false)
+		if status.RestartCount > 0 {
+			save(status.Name, true)
+		}
+	}
+	return errs
+}
+
+// collectCustomResources saves all namespaced custom resources found in the given namespace
+func (c *ContextData) collectCustomResources(ctx context.Context, namespace, dir string) []error {
+	crds, err := c.Clients.ExtClient.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []error{fmt.Errorf("unable to list custom resource definitions: %v", err)}
+	}
+
+	var errs []error
+	for _, crd := range crds.Items {
+		if crd.Spec.Scope != apiextensionsv1.NamespaceScoped {
+			continue
+		}
+		for _, version := range crd.Spec.Versions {
+			if !version.Storage {
+				continue
+			}
+			gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: version.Name, Resource: crd.Spec.Names.Plural}
+			list, err := c.Clients.DynClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("unable to list %s: %v", crd.Name, err))
+				continue
+			}
+			if len(list.Items) == 0 {
+				continue
+			}
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return append(errs, err)
+			}
+			if err := writeYaml(filepath.Join(dir, crd.Name+".yaml"), list); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// collectOperatorDiagnostics saves the operators configuration from the OperatorMap
+// along with the logs from the operator pods
+func (c *ContextData) collectOperatorDiagnostics(ctx context.Context, dir string) []error {
+	var errs []error
+	var infos []operatorInfo
+	for operatorType, operator := range c.OperatorMap {
+		infos = append(infos, operatorInfo{
+			Type:       operatorType.String(),
+			Name:       operator.Name(),
+			Namespace:  operator.Namespace(),
+			Image:      operator.Image(),
+			APIVersion: operator.APIVersion(),
+			GroupName:  operator.GroupName(),
+			CRDNames:   operator.CRDNames(),
+		})
+
+		deployment, err := c.Clients.KubeClient.AppsV1().Deployments(operator.Namespace()).Get(ctx, operator.Name(), metav1.GetOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to retrieve operator %s deployment: %v", operator.Name(), err))
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pods, err := c.Clients.KubeClient.CoreV1().Pods(operator.Namespace()).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to list operator %s pods: %v", operator.Name(), err))
+			continue
+		}
+		for _, pod := range pods.Items {
+			errs = append(errs, c.collectPodLogs(ctx, pod, filepath.Join(dir, "operators", safePathName(operator.Name())))...)
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	if err := writeYaml(filepath.Join(dir, "operators.yaml"), infos); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func writeYaml(file string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
@@ -0,0 +1,53 @@
+package framework_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestCollectDiagnostics validates the layout of the diagnostics bundle
+func TestCollectDiagnostics(t *testing.T) {
+	ctx := context.Background()
+	f, err := framework.NewFrameworkBuilder("diag").WithContexts(framework.FakeContext).WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+	ctxData := f.GetFirstContext()
+	defer f.AfterEachWithContext(ctx)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: ctxData.Namespace},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "sender", RestartCount: 1}},
+		},
+	}
+	if _, err := ctxData.Clients.KubeClient.CoreV1().Pods(ctxData.Namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unable to create pod: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := f.CollectDiagnostics(ctx, dir); err != nil {
+		t.Fatalf("unexpected error collecting diagnostics: %v", err)
+	}
+
+	nsDir := filepath.Join(dir, framework.FakeContext, ctxData.Namespace)
+	for _, file := range []string{
+		filepath.Join(nsDir, "pods.yaml"),
+		filepath.Join(nsDir, "deployments.yaml"),
+		filepath.Join(nsDir, "statefulsets.yaml"),
+		filepath.Join(nsDir, "events.yaml"),
+		filepath.Join(nsDir, "logs", "client-sender.log"),
+		filepath.Join(nsDir, "logs", "client-sender.previous.log"),
+		filepath.Join(dir, framework.FakeContext, "operators.yaml"),
+	} {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("expected file not found: %v", err)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package framework
+
+import (
+	gocontext "context"
+	"fmt"
+	"time"
+
+	openapiv1 "github.com/openshift/api/project/v1"
+	networkfake "github.com/openshift/client-go/network/clientset/versioned/fake"
+	projectfake "github.com/openshift/client-go/project/clientset/versioned/fake"
+	routefake "github.com/openshift/client-go/route/clientset/versioned/fake"
+	"github.com/rh-messaging/shipshape/pkg/framework/events"
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+	"github.com/rh-messaging/shipshape/pkg/framework/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	extfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubeinformers "k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const (
+	// FakeContext is the context name used when the Framework is built
+	// with fake clients and no context is available
+	FakeContext = "fake"
+	// FakeServerVersion is the server version reported by fake clients
+	FakeServerVersion = "1.24"
+)
+
+// NewFakeClientSet returns a ClientSet populated with fake clientsets,
+// where the kubernetes clientset is seeded with the given objects.
+// Objects created with a GenerateName get a random name assigned,
+// as it happens on a real cluster.
+func NewFakeClientSet(objects ...runtime.Object) ClientSet {
+	kubeClient := kubefake.NewSimpleClientset(objects...)
+	kubeClient.PrependReactor("create", "*", generateNameReactor)
+	kubeClient.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{
+		Major:      "1",
+		Minor:      "24",
+		GitVersion: "v" + FakeServerVersion + ".0",
+	}
+
+	projectClient := projectfake.NewSimpleClientset()
+	projectClient.PrependReactor("create", "projectrequests", projectRequestReactor(projectClient.Tracker()))
+
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, gvr := range resourceMap {
+		listKinds[gvr] = fmt.Sprintf("%sList", resourceKind(gvr))
+	}
+
+	return ClientSet{
+		KubeClient: kubeClient,
+		ExtClient:  extfake.NewSimpleClientset(),
+		DynClient:  dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme, listKinds),
+		OcpClient: ocpClient{
+			RoutesClient:   routefake.NewSimpleClientset(),
+			NetworkClient:  networkfake.NewSimpleClientset(),
+			ProjectsClient: projectClient,
+		},
+	}
+}
+
+// resourceKind returns the kind for the known resources from resourceMap
+func resourceKind(gvr schema.GroupVersionResource) string {
+	switch gvr.Resource {
+	case "issuers":
+		return "Issuer"
+	case "certificates":
+		return "Certificate"
+	case "deployments":
+		return "Deployment"
+	default:
+		return gvr.Resource
+	}
+}
+
+// generateNameReactor assigns a random name to objects being created
+// with a GenerateName, as fake clientsets do not do it on their own
+func generateNameReactor(action k8stesting.Action) (bool, runtime.Object, error) {
+	create, ok := action.(k8stesting.CreateAction)
+	if !ok {
+		return false, nil, nil
+	}
+	if obj, ok := create.GetObject().(metav1.Object); ok && obj.GetName() == "" && obj.GetGenerateName() != "" {
+		obj.SetName(obj.GetGenerateName() + util.String(5))
+	}
+	return false, nil, nil
+}
+
+// projectRequestReactor simulates the creation of a Project from a ProjectRequest
+func projectRequestReactor(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		request, ok := action.(k8stesting.CreateAction).GetObject().(*openapiv1.ProjectRequest)
+		if !ok {
+			return false, nil, nil
+		}
+		name := request.Name
+		if name == "" {
+			name = request.GenerateName + util.String(5)
+		}
+		project := &openapiv1.Project{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: request.Labels,
+			},
+			Status: openapiv1.ProjectStatus{Phase: corev1.NamespaceActive},
+		}
+		if err := tracker.Add(project); err != nil {
+			return true, nil, err
+		}
+		return true, project, nil
+	}
+}
+
+// beforeEachFake initializes the given contexts using fake clients
+func (f *Framework) beforeEachFake(ctx gocontext.Context, contexts ...string) error {
+	namespaceLabels := map[string]string{
+		"e2e-framework": f.BaseName,
+	}
+
+	for _, context := range contexts {
+		clients := NewFakeClientSet(f.fakeObjects...)
+		isOpenShift := f.IsOpenshift
+
+		var name string
+		var namespace *corev1.Namespace
+		var project *openapiv1.Project
+		var err error
+		if !f.IsOpenshift {
+			namespace, err = generateNamespaceWithContext(ctx, clients.KubeClient, f.BaseName, namespaceLabels)
+			if err == nil {
+				name = namespace.Name
+			}
+		} else {
+			project, err = generateProjectWithContext(ctx, clients.OcpClient.ProjectsClient, f.BaseName, namespaceLabels)
+			if err == nil {
+				name = project.Name
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("unable to set up namespace on context %s: %v", context, err)
+		}
+
+		ctxData := &ContextData{
+			Id:            context,
+			Namespace:     name,
+			UniqueName:    name,
+			Clients:       clients,
+			ServerVersion: FakeServerVersion,
+			isOpenShift:   &isOpenShift,
+			OperatorMap:   map[operators.OperatorType]operators.OperatorSetup{},
+			Tracker:       newResourceTracker(clients.DynClient),
+		}
+		f.ContextMap[context] = ctxData
+		ctxData.AddNamespacesToDelete(namespace)
+		ctxData.AddProjectsToDelete(project)
+
+		// Simulating the operators
+		for _, builder := range f.operatorBuilders(context) {
+			operator := &fakeOperator{
+				name:       builder.OperatorType().String(),
+				namespace:  name,
+				kubeClient: clients.KubeClient,
+			}
+			if info, ok := builder.(operators.OperatorBuilderInfo); ok {
+				operator.name = info.OperatorName()
+				operator.image = info.OperatorImage()
+			}
+			ctxData.OperatorMap[builder.OperatorType()] = operator
+		}
+
+		options := kubeinformers.WithNamespace(name)
+		informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(clients.KubeClient, time.Second*30, options)
+		ctxData.EventHandler = events.EventHandler{}
+		ctxData.EventHandler.CreateEventInformers(informerFactory)
+	}
+
+	return f.SetupWithContext(ctx)
+}
+
+// fakeOperator simulates an operator, through a deployment
+// that is reported as ready as soon as it gets created
+type fakeOperator struct {
+	name       string
+	image      string
+	namespace  string
+	kubeClient clientset.Interface
+}
+
+func (o *fakeOperator) Interface() interface{} {
+	return nil
+}
+
+func (o *fakeOperator) Namespace() string {
+	return o.namespace
+}
+
+func (o *fakeOperator) Image() string {
+	return o.image
+}
+
+func (o *fakeOperator) Name() string {
+	return o.name
+}
+
+func (o *fakeOperator) CRDNames() []string {
+	return []string{}
+}
+
+func (o *fakeOperator) GroupName() string {
+	return ""
+}
+
+func (o *fakeOperator) APIVersion() string {
+	return ""
+}
+
+func (o *fakeOperator) Setup() error {
+	log.Logf("Simulating operator %s on namespace %s", o.name, o.namespace)
+	err := o.CreateDeployment()
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (o *fakeOperator) UpdateDeployment(deployment *appsv1.Deployment) error {
+	_, err := o.kubeClient.AppsV1().Deployments(o.namespace).Update(gocontext.TODO(), deployment, metav1.UpdateOptions{})
+	return err
+}
+
+func (o *fakeOperator) DeleteDeployment() error {
+	return o.kubeClient.AppsV1().Deployments(o.namespace).Delete(gocontext.TODO(), o.name, metav1.DeleteOptions{})
+}
+
+func (o *fakeOperator) CreateDeployment() error {
+	labels := map[string]string{"name": o.name}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.name,
+			Namespace: o.namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: Int32Ptr(1),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: o.name, Image: o.image}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          1,
+			AvailableReplicas: 1,
+			UpdatedReplicas:   1,
+			ReadyReplicas:     1,
+		},
+	}
+	_, err := o.kubeClient.AppsV1().Deployments(o.namespace).Create(gocontext.TODO(), deployment, metav1.CreateOptions{})
+	return err
+}
+
+func (o *fakeOperator) GetDeployment() (*appsv1.Deployment, error) {
+	return o.kubeClient.AppsV1().Deployments(o.namespace).Get(gocontext.TODO(), o.name, metav1.GetOptions{})
+}
+
+// Upgrade simulates an upgrade by replacing the image of the operator deployment
+func (o *fakeOperator) Upgrade(upgrade operators.OperatorUpgrade) error {
+	deployment, err := o.GetDeployment()
+	if err != nil {
+		return err
+	}
+	if upgrade.Image != "" {
+		log.Logf("Simulating upgrade of operator %s to %s", o.name, upgrade.Image)
+		o.image = upgrade.Image
+		deployment.Spec.Template.Spec.Containers[0].Image = upgrade.Image
+	}
+	return o.UpdateDeployment(deployment)
+}
+
+func (o *fakeOperator) TeardownEach() error {
+	err := o.DeleteDeployment()
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (o *fakeOperator) TeardownSuite() error {
+	return nil
+}
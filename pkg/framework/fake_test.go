@@ -0,0 +1,120 @@
+package framework_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestFakeClients validates that a Framework can be built and torn down
+// using fake clients, with namespaces and operators being simulated
+func TestFakeClients(t *testing.T) {
+	ctx := context.Background()
+	seed := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "seed", Namespace: "default"}}
+	f, err := framework.NewFrameworkBuilder("fake").WithContexts(framework.FakeContext).WithFakeClients(seed).BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+
+	ctxData := f.GetFirstContext()
+	if ctxData == nil || ctxData.Id != framework.FakeContext {
+		t.Fatalf("expected context %s, got: %+v", framework.FakeContext, ctxData)
+	}
+	if !strings.HasPrefix(ctxData.Namespace, "shipshape-fake-") {
+		t.Errorf("unexpected namespace: %s", ctxData.Namespace)
+	}
+	if _, err := ctxData.Clients.KubeClient.CoreV1().Namespaces().Get(ctx, ctxData.Namespace, metav1.GetOptions{}); err != nil {
+		t.Errorf("namespace not created: %v", err)
+	}
+	if _, err := ctxData.Clients.KubeClient.CoreV1().ConfigMaps("default").Get(ctx, "seed", metav1.GetOptions{}); err != nil {
+		t.Errorf("seed object not found: %v", err)
+	}
+	if ctxData.IsOpenShift() {
+		t.Errorf("fake context should not be OpenShift")
+	}
+
+	// Operators are simulated by ready deployments
+	if len(ctxData.OperatorMap) != len(operators.SupportedOperators) {
+		t.Errorf("operators, got: %d, expected: %d", len(ctxData.OperatorMap), len(operators.SupportedOperators))
+	}
+	for _, operator := range ctxData.OperatorMap {
+		deployment, err := ctxData.GetDeploymentWithContext(ctx, operator.Name())
+		if err != nil {
+			t.Errorf("deployment for operator %s not found: %v", operator.Name(), err)
+			continue
+		}
+		if deployment.Status.ReadyReplicas != 1 {
+			t.Errorf("deployment for operator %s is not ready", operator.Name())
+		}
+	}
+	// Upgrades are simulated by replacing the operator image
+	operator := ctxData.OperatorMap[operators.OperatorTypeBroker]
+	if err := operator.Upgrade(operators.OperatorUpgrade{Image: "upgraded:latest"}); err != nil {
+		t.Errorf("unexpected error upgrading operator: %v", err)
+	} else if deployment, _ := operator.GetDeployment(); deployment.Spec.Template.Spec.Containers[0].Image != "upgraded:latest" {
+		t.Errorf("operator image not upgraded: %s", deployment.Spec.Template.Spec.Containers[0].Image)
+	}
+	if _, err := ctxData.ListPodsForDeploymentNameWithContext(ctx, "invalid"); err == nil {
+		t.Errorf("expected error listing pods for invalid deployment")
+	}
+
+	// Cleanup functions run in reverse order on teardown
+	var cleanups []string
+	for _, name := range []string{"first", "second"} {
+		name := name
+		ctxData.AddCleanup(func(ctx context.Context) error {
+			cleanups = append(cleanups, name)
+			return nil
+		})
+	}
+
+	if err := f.AfterEachWithContext(ctx); err != nil {
+		t.Errorf("unexpected error on teardown: %v", err)
+	}
+	if strings.Join(cleanups, ",") != "second,first" {
+		t.Errorf("unexpected cleanups: %v", cleanups)
+	}
+}
+
+// TestBuildWithoutContexts validates that failing to load the contexts is
+// reported when building, unless fake clients are used
+func TestBuildWithoutContexts(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/nonexistent/kubeconfig")
+	ctx := context.Background()
+	builder := framework.NewFrameworkBuilder("nocontexts")
+	if _, err := builder.BuildWithContext(ctx); err == nil || !strings.Contains(err.Error(), "unable to load contexts") {
+		t.Errorf("expected error loading contexts, got: %v", err)
+	}
+	f, err := builder.WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework with fake clients: %v", err)
+	}
+	if err := f.AfterEachWithContext(ctx); err != nil {
+		t.Errorf("unexpected error on teardown: %v", err)
+	}
+}
+
+// TestFirstContextOrder validates that the first context is the first one
+// given to the builder, regardless of the ContextMap iteration order
+func TestFirstContextOrder(t *testing.T) {
+	ctx := context.Background()
+	f, err := framework.NewFrameworkBuilder("order").WithContexts("east", "west", "north", "south").WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+	defer func() {
+		if err := f.AfterEachWithContext(ctx); err != nil {
+			t.Errorf("unexpected error on teardown: %v", err)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		if ctxData := f.GetFirstContext(); ctxData == nil || ctxData.Id != "east" {
+			t.Fatalf("unexpected first context: %+v", ctxData)
+		}
+	}
+}
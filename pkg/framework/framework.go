@@ -18,7 +18,7 @@ import (
 	gocontext "context"
 	"fmt"
 	"os"
-	"strings"
+	"sync"
 	"time"
 
 	brokerbeta "github.com/artemiscloud/activemq-artemis-operator/api/v1beta1"
@@ -38,7 +38,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	networkv1 "github.com/openshift/client-go/network/clientset/versioned"
 	projectv1 "github.com/openshift/client-go/project/clientset/versioned"
@@ -51,6 +53,7 @@ import (
 	kubeinformers "k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
@@ -71,9 +74,9 @@ type ClientSet struct {
 }
 
 type ocpClient struct {
-	RoutesClient   *routev1.Clientset
-	NetworkClient  *networkv1.Clientset
-	ProjectsClient *projectv1.Clientset
+	RoutesClient   routev1.Interface
+	NetworkClient  networkv1.Interface
+	ProjectsClient projectv1.Interface
 }
 
 func contains(target operators.OperatorType, collection []operators.OperatorType) bool {
@@ -103,23 +106,34 @@ type ContextData struct {
 	isOpenShift        *bool
 	EventHandler       events.EventHandler
 	ServerVersion      string
+	// Connection details for this context, used by SPDY based
+	// helpers like Execute and PortForward
+	restConfig *rest.Config
+	rawConfig  *clientcmdapi.Config
+	// Tracker records the resources created through the framework helpers
+	// and the functions registered through AddCleanup, which are removed
+	// (or called) when the Framework runs AfterEach
+	Tracker *ResourceTracker
 }
 
 type Framework struct {
-	BaseName   string
-	restConfig rest.Config
+	BaseName string
 
 	// Map that ties clients and namespaces for each available context
 	ContextMap            map[string]*ContextData
-	IsOpenshift           bool // Namespace/Project
-	SkipNamespaceCreation bool // Whether to skip creating a namespace
+	contexts              []string // context names, in the order they were given
+	IsOpenshift           bool     // Namespace/Project
+	SkipNamespaceCreation bool     // Whether to skip creating a namespace
 	cleanupHandleEach     CleanupActionHandle
 	cleanupHandleSuite    CleanupActionHandle
 	afterEachDone         bool
 	builders              []operators.OperatorSetupBuilder
+	contextBuilders       map[string][]operators.OperatorSetupBuilder
 	globalOperatorFlag    bool
 	globalBaseName        string
 	globalGeneratedName   string
+	fakeClients           bool
+	fakeObjects           []runtime.Object
 }
 
 // Framework Builder type
@@ -128,23 +142,30 @@ type Builder struct {
 	contexts       []string
 	isOpenshift    bool
 	globalOperator bool
+	fakeClients    bool
+	fakeObjects    []runtime.Object
+	// Error loading the default contexts, reported on Build
+	contextsErr error
 }
 
 // Helper for building frameworks with possible customizations
 func NewFrameworkBuilder(baseName string) Builder {
-	// In case no contexts available
-	if len(TestContext.GetContexts()) == 0 {
-		panic("No contexts available. Unable to create an instance of the Shipshape Framework.")
-	}
-
 	b := Builder{
 		f: &Framework{
 			BaseName:   baseName,
 			ContextMap: make(map[string]*ContextData),
 		},
-		contexts:    []string{TestContext.GetContexts()[0]},
 		isOpenshift: false,
 	}
+
+	// Contexts are not needed when using fake clients, so failing to
+	// load them is only reported when the framework gets built
+	contexts, err := TestContext.LoadContexts()
+	if err != nil {
+		b.contextsErr = fmt.Errorf("unable to load contexts: %v", err)
+	} else if len(contexts) > 0 {
+		b.contexts = []string{contexts[0]}
+	}
 	return b
 }
 
@@ -177,24 +198,109 @@ func (b Builder) WithBuilders(builders ...operators.OperatorSetupBuilder) Builde
 	return b
 }
 
+// Customize builders for a given context only, so that each context can run
+// its own set of operators (or different versions of them). Builders cannot be
+// shared with other contexts, as they are bound to the context when building.
+func (b Builder) WithContextBuilders(context string, builders ...operators.OperatorSetupBuilder) Builder {
+	b.f.SetContextOperatorBuilders(context, builders...)
+	return b
+}
+
+// WithFakeClients makes the Framework use fake clientsets (seeded with the
+// given objects) instead of connecting to a cluster. Namespaces are created
+// in the fake clientsets and operators are simulated by ready deployments,
+// so no kubeconfig is needed. Executing commands and port-forwarding are not
+// available in this mode.
+func (b Builder) WithFakeClients(objects ...runtime.Object) Builder {
+	b.fakeClients = true
+	b.fakeObjects = objects
+	if len(b.contexts) == 0 {
+		b.contexts = []string{FakeContext}
+	}
+	return b
+}
+
 // Generates and initialize the Framework
 func (b Builder) Build() *Framework {
+	err := b.init()
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
 	// Initialize restConfig and kube clients for each provided context
-	b.f.IsOpenshift = b.isOpenshift
-	b.f.globalOperatorFlag = b.globalOperator
 	b.f.BeforeEach(b.contexts...)
 	return b.f
 }
 
+// BuildWithContext generates and initializes the Framework, returning an
+// error instead of failing the running spec
+func (b Builder) BuildWithContext(ctx gocontext.Context) (*Framework, error) {
+	if err := b.init(); err != nil {
+		return nil, err
+	}
+	if err := b.f.BeforeEachWithContext(ctx, b.contexts...); err != nil {
+		return nil, err
+	}
+	return b.f, nil
+}
+
+func (b Builder) init() error {
+	// In case no contexts available
+	if len(b.contexts) == 0 {
+		if b.contextsErr != nil {
+			return fmt.Errorf("no contexts available: %v", b.contextsErr)
+		}
+		return fmt.Errorf("no contexts available, unable to create an instance of the Shipshape Framework")
+	}
+	b.f.IsOpenshift = b.isOpenshift
+	b.f.globalOperatorFlag = b.globalOperator
+	b.f.fakeClients = b.fakeClients
+	b.f.fakeObjects = b.fakeObjects
+	return nil
+}
+
 // Defines a custom set of builders for the given Framework instance
 func (f *Framework) SetOperatorBuilders(builders ...operators.OperatorSetupBuilder) {
 	f.builders = builders
 }
 
+// Defines a custom set of builders for the given context, overriding
+// the builders shared by all contexts
+func (f *Framework) SetContextOperatorBuilders(context string, builders ...operators.OperatorSetupBuilder) {
+	if f.contextBuilders == nil {
+		f.contextBuilders = map[string][]operators.OperatorSetupBuilder{}
+	}
+	f.contextBuilders[context] = builders
+}
+
+// operatorBuilders returns the builders to use on the given context, populating
+// the shared builders with the supported operators when none has been defined
+func (f *Framework) operatorBuilders(context string) []operators.OperatorSetupBuilder {
+	if builders, ok := f.contextBuilders[context]; ok {
+		return builders
+	}
+	if f.builders == nil || len(f.builders) == 0 {
+		// populate builders with default values
+		for _, builder := range operators.SupportedOperators {
+			f.builders = append(f.builders, builder)
+		}
+	}
+	return f.builders
+}
+
 // BeforeEach gets clients and makes a namespace
 func (f *Framework) BeforeEach(contexts ...string) {
+	err := f.BeforeEachWithContext(gocontext.TODO(), contexts...)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+}
+
+// BeforeEachWithContext gets clients, makes a namespace and sets up the operators
+// for each of the given contexts, returning an error instead of failing the running spec
+func (f *Framework) BeforeEachWithContext(ctx gocontext.Context, contexts ...string) error {
 	f.cleanupHandleEach = AddCleanupAction(AfterEach, f.AfterEach)
 	f.cleanupHandleSuite = AddCleanupAction(AfterSuite, f.AfterSuite)
+	f.contexts = contexts
+
+	if f.fakeClients {
+		return f.beforeEachFake(ctx, contexts...)
+	}
 
 	// Loop through contexts
 	// 1 - Set the current context
@@ -207,317 +313,433 @@ func (f *Framework) BeforeEach(contexts ...string) {
 	}
 
 	config, err := clientcmd.LoadFromFile(TestContext.KubeConfig)
-	//if err != nil || config == nil {
-	//	fmt.Sprintf("Unable to retrieve config from %s - %s", TestContext.KubeConfig, err))
-	//}
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	if err != nil {
+		return fmt.Errorf("unable to retrieve config from %s: %v", TestContext.KubeConfig, err)
+	}
 
 	namespaceLabels := map[string]string{
 		"e2e-framework": f.BaseName,
 	}
 
-	// Loop through provided contexts (or use current-context)
-	// and loading all context info
-	for _, context := range contexts {
-
-		// Populating ContextMap with clients for each provided context
-		var clients ClientSet
+	// In global operator mode, operators are installed into openshift-operators and
+	// only the first context gets a new namespace, the others using the namespace of
+	// their kubeconfig context. Namespaces are not removed in this mode, so this is
+	// decided before the contexts are set up concurrently.
+	createNamespace := map[string]bool{}
+	for i, context := range contexts {
+		createNamespace[context] = !f.SkipNamespaceCreation && (!f.globalOperatorFlag || i == 0)
+	}
+	if f.globalOperatorFlag {
+		f.SkipNamespaceCreation = true //dont remove the global namespace, d'oh
+	}
 
-		// Set current context and serialize config
-		config.CurrentContext = context
-		bytes, err := clientcmd.Write(*config)
-		if err != nil {
-			ginkgo.Fail(fmt.Sprintf("Unable to serialize config %s - %s", TestContext.KubeConfig, err))
+	// Loop through provided contexts (or use current-context)
+	// and loading all context info concurrently
+	var lock sync.Mutex
+	err = runConcurrently(contexts, func(context string, logger *contextLogger) error {
+		return f.setupContext(ctx, config, context, createNamespace[context], namespaceLabels, logger, &lock)
+	})
+	if err != nil {
+		if cleanupErr := f.AfterEachWithContext(ctx); cleanupErr != nil {
+			log.Logf("error cleaning up after failed setup: %v", cleanupErr)
 		}
+		return err
+	}
 
-		// Generating restConfig
-		clientConfig, err := clientcmd.NewClientConfigFromBytes(bytes)
-		gomega.Expect(err).NotTo(gomega.HaveOccurred())
-		restConfig, err := clientConfig.ClientConfig()
-
-		if restConfig.NegotiatedSerializer == nil {
-			klog.Warningf("restconfig has no serializer!")
-
-			brokeralpha1.AddToScheme(scheme.Scheme)
-			brokeralpha2.AddToScheme(scheme.Scheme)
-			brokeralpha3.AddToScheme(scheme.Scheme)
-			brokeralpha4.AddToScheme(scheme.Scheme)
-			brokeralpha5.AddToScheme(scheme.Scheme)
-			brokerbeta.AddToScheme(scheme.Scheme)
-			restConfig.NegotiatedSerializer = serializer.NewCodecFactory(scheme.Scheme)
-		}
-		gomega.Expect(err).NotTo(gomega.HaveOccurred())
-		f.restConfig = *restConfig
-		rawConfig, err := clientConfig.RawConfig()
-		gomega.Expect(err).NotTo(gomega.HaveOccurred())
-
-		// Create the client instances
-		log.Logf("config: %v", restConfig)
-		kubeClient, err := clientset.NewForConfig(restConfig)
-		gomega.Expect(err).NotTo(gomega.HaveOccurred())
-		extClient, err := apiextension.NewForConfig(restConfig)
-		gomega.Expect(err).NotTo(gomega.HaveOccurred())
-		dynClient, err := dynamic.NewForConfig(restConfig)
-		gomega.Expect(err).NotTo(gomega.HaveOccurred())
-
-		projectClient, err := projectv1.NewForConfig(restConfig)
-		gomega.Expect(err).NotTo(gomega.HaveOccurred())
-
-		// Initilizing the ClientSet for context
-		clients = ClientSet{
-			KubeClient: kubeClient,
-			ExtClient:  extClient,
-			DynClient:  dynClient,
+	// setup the operators
+	err = f.SetupWithContext(ctx)
+	if err != nil {
+		if cleanupErr := f.AfterEachWithContext(ctx); cleanupErr != nil {
+			log.Logf("error cleaning up after failed setup: %v", cleanupErr)
 		}
+	}
+	return err
+}
 
-		discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
-		if err != nil {
-			log.Logf(" error in discoveryClient %v", err)
-		}
-		serverVerInfo, err := discoveryClient.ServerVersion()
-		if err != nil {
-			log.Logf("Error while fetching server version information", err)
-		}
-		serverVersion := serverVerInfo.Major + "." + serverVerInfo.Minor
-
-		// Generating the namespace on provided contexts
-		ginkgo.By(fmt.Sprintf("Building namespace api objects, basename %s", f.BaseName))
-		// Keep original label for now (maybe we can remove or rename later)
-		var namespace *corev1.Namespace
-		var project *openapiv1.Project
-		if !f.SkipNamespaceCreation {
-			if !f.IsOpenshift {
-				log.Logf("Setting up namespace")
-				namespace = generateNamespace(kubeClient, f.BaseName, namespaceLabels)
-			} else {
-				log.Logf("Setting up project")
-				project = generateProject(projectClient, f.BaseName, namespaceLabels)
-			}
-		} else {
-			tempCtx := rawConfig.Contexts[context]
-			if !f.IsOpenshift {
-				namespace, err = kubeClient.CoreV1().Namespaces().Get(gocontext.TODO(), tempCtx.Namespace, metav1.GetOptions{})
-			} else {
-				project, err = projectClient.ProjectV1().Projects().Get(gocontext.TODO(), tempCtx.Namespace, metav1.GetOptions{})
-			}
-		}
-		if !f.IsOpenshift {
-			gomega.Expect(namespace).NotTo(gomega.BeNil())
-		} else {
-			gomega.Expect(project).NotTo(gomega.BeNil())
-		}
+// setupContext generates the clients and the namespace (unless the namespace of
+// the kubeconfig context must be used) for the given context, building its operators
+// and adding it to the ContextMap. The given lock guards the state shared by contexts
+// being set up concurrently.
+func (f *Framework) setupContext(ctx gocontext.Context, config *clientcmdapi.Config, context string, createNamespace bool, namespaceLabels map[string]string, logger *contextLogger, lock *sync.Mutex) error {
+	// Populating ContextMap with clients for each provided context
+	var clients ClientSet
+
+	// Set current context and serialize config
+	kubeConfig := config.DeepCopy()
+	kubeConfig.CurrentContext = context
+	bytes, err := clientcmd.Write(*kubeConfig)
+	if err != nil {
+		return fmt.Errorf("unable to serialize config %s - %s", TestContext.KubeConfig, err)
+	}
 
-		// Verify if Cert Manager is installed
-		_, err = extClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(gocontext.TODO(), "issuers.certmanager.k8s.io", metav1.GetOptions{})
-		certManagerPresent := false
-		if err == nil {
-			certManagerPresent = true
-		} else if _, err = extClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(gocontext.TODO(), "issuers.cert-manager.io", metav1.GetOptions{}); err == nil {
-			certManagerPresent = true
-		}
+	// Generating restConfig
+	clientConfig, err := clientcmd.NewClientConfigFromBytes(bytes)
+	if err != nil {
+		return err
+	}
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("unable to load rest config for context %s: %v", context, err)
+	}
+
+	if restConfig.NegotiatedSerializer == nil {
+		klog.Warningf("restconfig has no serializer!")
+
+		lock.Lock()
+		brokeralpha1.AddToScheme(scheme.Scheme)
+		brokeralpha2.AddToScheme(scheme.Scheme)
+		brokeralpha3.AddToScheme(scheme.Scheme)
+		brokeralpha4.AddToScheme(scheme.Scheme)
+		brokeralpha5.AddToScheme(scheme.Scheme)
+		brokerbeta.AddToScheme(scheme.Scheme)
+		restConfig.NegotiatedSerializer = serializer.NewCodecFactory(scheme.Scheme)
+		lock.Unlock()
+	}
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return err
+	}
+
+	// Create the client instances
+	logger.Logf("config: %v", restConfig)
+	kubeClient, err := clientset.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	extClient, err := apiextension.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	dynClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	projectClient, err := projectv1.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	// Initilizing the ClientSet for context
+	clients = ClientSet{
+		KubeClient: kubeClient,
+		ExtClient:  extClient,
+		DynClient:  dynClient,
+	}
 
-		// Initializing the context
-		var name string
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("error in discoveryClient: %v", err)
+	}
+	serverVerInfo, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return fmt.Errorf("error while fetching server version information: %v", err)
+	}
+	serverVersion := serverVerInfo.Major + "." + serverVerInfo.Minor
+
+	// Generating the namespace on provided contexts
+	logger.Logf("Building namespace api objects, basename %s", f.BaseName)
+	// Keep original label for now (maybe we can remove or rename later)
+	var namespace *corev1.Namespace
+	var project *openapiv1.Project
+	if createNamespace {
 		if !f.IsOpenshift {
-			name = namespace.GetName()
+			logger.Logf("Setting up namespace")
+			namespace, err = generateNamespaceWithContext(ctx, kubeClient, f.BaseName, namespaceLabels)
 		} else {
-			name = project.GetName()
+			logger.Logf("Setting up project")
+			project, err = generateProjectWithContext(ctx, projectClient, f.BaseName, namespaceLabels)
 		}
-
-		ctx := &ContextData{
-			Id:                 context,
-			Namespace:          name,
-			UniqueName:         name,
-			Clients:            clients,
-			CertManagerPresent: certManagerPresent,
-			ServerVersion:      serverVersion,
+	} else {
+		tempCtx := rawConfig.Contexts[context]
+		if !f.IsOpenshift {
+			namespace, err = kubeClient.CoreV1().Namespaces().Get(ctx, tempCtx.Namespace, metav1.GetOptions{})
+		} else {
+			project, err = projectClient.ProjectV1().Projects().Get(ctx, tempCtx.Namespace, metav1.GetOptions{})
 		}
-		f.ContextMap[context] = ctx
+	}
+	if err != nil {
+		return fmt.Errorf("unable to set up namespace on context %s: %v", context, err)
+	}
 
-		// OpenShift specific initialization
-		if ctx.IsOpenShift() {
-			ctx.Clients.OcpClient.RoutesClient, err = routev1.NewForConfig(restConfig)
-			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	// Verify if Cert Manager is installed
+	_, err = extClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(ctx, "issuers.certmanager.k8s.io", metav1.GetOptions{})
+	certManagerPresent := false
+	if err == nil {
+		certManagerPresent = true
+	} else if _, err = extClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(ctx, "issuers.cert-manager.io", metav1.GetOptions{}); err == nil {
+		certManagerPresent = true
+	}
 
-			ctx.Clients.OcpClient.NetworkClient, err = networkv1.NewForConfig(restConfig)
-			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	// Initializing the context
+	var name string
+	if !f.IsOpenshift {
+		name = namespace.GetName()
+	} else {
+		name = project.GetName()
+	}
 
-			ctx.Clients.OcpClient.ProjectsClient, err = projectv1.NewForConfig(restConfig)
-			gomega.Expect(err).NotTo(gomega.HaveOccurred())
-			ctx.projectsToDelete = append(ctx.projectsToDelete, project)
+	ctxData := &ContextData{
+		Id:                 context,
+		Namespace:          name,
+		UniqueName:         name,
+		Clients:            clients,
+		CertManagerPresent: certManagerPresent,
+		ServerVersion:      serverVersion,
+		restConfig:         restConfig,
+		rawConfig:          &rawConfig,
+		Tracker:            newResourceTracker(dynClient),
+	}
+	if !f.SkipNamespaceCreation {
+		ctxData.AddNamespacesToDelete(namespace)
+	}
+	lock.Lock()
+	f.ContextMap[context] = ctxData
+	lock.Unlock()
+
+	// OpenShift specific initialization
+	if ctxData.IsOpenShift() {
+		if ctxData.Clients.OcpClient.RoutesClient, err = routev1.NewForConfig(restConfig); err != nil {
+			return err
 		}
-
-		// Initializing needed operators on given context
-		ctx.OperatorMap = map[operators.OperatorType]operators.OperatorSetup{}
-		if f.builders == nil || len(f.builders) == 0 {
-			// populate builders with default values
-			for _, builder := range operators.SupportedOperators {
-				f.builders = append(f.builders, builder)
-			}
+		if ctxData.Clients.OcpClient.NetworkClient, err = networkv1.NewForConfig(restConfig); err != nil {
+			return err
 		}
-		for _, builder := range f.builders {
-			builder.NewBuilder(restConfig, &rawConfig)
-			builder.WithNamespace(name)
-
-			if !f.globalOperatorFlag {
-				log.Logf("no global flag, building local operator")
-				operator, err := builder.Build()
-				gomega.Expect(err).NotTo(gomega.HaveOccurred())
-				ctx.OperatorMap[builder.OperatorType()] = operator
-			} else {
-				log.Logf("global flag, installing into openshift-operators instead")
-				builder.WithNamespace("openshift-operators").WithGlobalNamespace()
-				f.SkipNamespaceCreation = true //dont remove the global namespace, d'oh
-				operator, err := builder.Build()
-				gomega.Expect(err).NotTo(gomega.HaveOccurred())
-				ctx.OperatorMap[builder.OperatorType()] = operator
-			}
+		if ctxData.Clients.OcpClient.ProjectsClient, err = projectv1.NewForConfig(restConfig); err != nil {
+			return err
 		}
+		ctxData.projectsToDelete = append(ctxData.projectsToDelete, project)
+	}
 
-		if !f.SkipNamespaceCreation {
-			ctx.AddNamespacesToDelete(namespace)
+	// Initializing needed operators on given context
+	// (builders are shared across contexts, so they cannot be used concurrently)
+	ctxData.OperatorMap = map[operators.OperatorType]operators.OperatorSetup{}
+	lock.Lock()
+	defer lock.Unlock()
+	for _, builder := range f.operatorBuilders(context) {
+		builder.NewBuilder(restConfig, &rawConfig)
+		builder.WithNamespace(name)
+		builder.WithResourceTracker(ctxData.Tracker)
+
+		if !f.globalOperatorFlag {
+			logger.Logf("no global flag, building local operator")
+		} else {
+			logger.Logf("global flag, installing into openshift-operators instead")
+			builder.WithNamespace("openshift-operators").WithGlobalNamespace()
 		}
-
-		options := kubeinformers.WithNamespace(name)
-		informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, time.Second*30, options)
-		ctx.EventHandler = events.EventHandler{}
-		ctx.EventHandler.CreateEventInformers(informerFactory)
+		operator, err := builder.Build()
+		if err != nil {
+			return fmt.Errorf("failed to build operator %v: %v", builder.OperatorType(), err)
+		}
+		ctxData.OperatorMap[builder.OperatorType()] = operator
 	}
 
-	// setup the operators
-	err = f.Setup()
-	if err != nil {
-		f.AfterEach()
-	}
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	options := kubeinformers.WithNamespace(name)
+	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, time.Second*30, options)
+	ctxData.EventHandler = events.EventHandler{}
+	ctxData.EventHandler.CreateEventInformers(informerFactory)
+	return nil
 }
 
 // AfterEach deletes the namespace, after reading its events.
 func (f *Framework) AfterEach() {
+	err := f.AfterEachWithContext(gocontext.TODO())
+	if err != nil {
+		log.Failf("%v", err)
+	}
+}
+
+// AfterEachWithContext tears down the operators and deletes the namespaces,
+// returning all errors found instead of failing the running spec.
+func (f *Framework) AfterEachWithContext(ctx gocontext.Context) error {
 	// In case already executed, skip
 	if f.afterEachDone {
-		return
+		return nil
 	}
 
 	// Remove cleanup action
 	RemoveCleanupAction(AfterEach, f.cleanupHandleEach)
 
+	// Save the diagnostics bundle before anything gets removed
+	f.collectDiagnosticsOnFailure(ctx)
+
+	var errs []error
+
+	// run the cleanup functions registered on each context and remove the
+	// resources tracked on them (namespaced ones are kept with their namespaces)
+	preserveNamespaced := preserveNamespaces()
+	if err := f.forEachContext(func(ctxData *ContextData, logger *contextLogger) error {
+		if ctxData.Tracker == nil {
+			return nil
+		}
+		return ctxData.Tracker.deleteEach(ctx, preserveNamespaced)
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
 	// teardown the operator
-	err := f.TeardownEach()
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	if err := f.TeardownEachWithContext(ctx); err != nil {
+		errs = append(errs, err)
+	}
 
-	// DeleteNamespace at the very end in defer, to avoid any
-	// expectation failures preventing deleting the namespace.
-	defer func() {
-		deleteionErrors := map[string][]error{}
-		// Whether to delete namespace is determined by 3 factors: delete-namespace flag, delete-namespace-on-failure flag and the test result
-		// if delete-namespace set to false, namespace will always be preserved.
-		// if delete-namespace is true and delete-namespace-on-failure is false, namespace will be preserved if test failed.
-		for _, contextData := range f.ContextMap {
-			if !f.IsOpenshift {
-				for _, ns := range contextData.namespacesToDelete {
-					ginkgo.By(fmt.Sprintf("Destroying namespace %q for this suite on all clusters.", ns.Name))
-					if errors := contextData.DeleteNamespace(ns); errors != nil {
-						deleteionErrors[ns.Name] = errors
-					}
+	// Delete namespaces even if teardown failed
+	deleteionErrors := map[string][]error{}
+	// Whether to delete namespace is determined by 3 factors: delete-namespace flag, delete-namespace-on-failure flag and the test result
+	// if delete-namespace set to false, namespace will always be preserved.
+	// if delete-namespace is true and delete-namespace-on-failure is false, namespace will be preserved if test failed.
+	for _, contextData := range f.ContextMap {
+		if !f.IsOpenshift {
+			for _, ns := range contextData.namespacesToDelete {
+				ginkgo.By(fmt.Sprintf("Destroying namespace %q for this suite on all clusters.", ns.Name))
+				if errors := contextData.DeleteNamespaceWithContext(ctx, ns); errors != nil {
+					deleteionErrors[ns.Name] = errors
 				}
-			} else {
-				for _, project := range contextData.projectsToDelete {
-					ginkgo.By(fmt.Sprintf("Destroying project %s for this suite on all clusters", project.Name))
-					if errors := contextData.DeleteProject(project); errors != nil {
-						deleteionErrors[project.Name] = errors
-					}
+			}
+		} else {
+			for _, project := range contextData.projectsToDelete {
+				ginkgo.By(fmt.Sprintf("Destroying project %s for this suite on all clusters", project.Name))
+				if errors := contextData.DeleteProjectWithContext(ctx, project); errors != nil {
+					deleteionErrors[project.Name] = errors
 				}
 			}
-
-			// Paranoia-- prevent reuse!
-			contextData.Namespace = ""
-			contextData.Clients.KubeClient = nil
-			contextData.namespacesToDelete = nil
-			contextData.projectsToDelete = nil
 		}
 
-		// if we had errors deleting, report them now.
-		if len(deleteionErrors) != 0 {
-			messages := []string{}
-			for namespaceKey, namespaceErrors := range deleteionErrors {
-				for clusterIdx, namespaceErr := range namespaceErrors {
-					messages = append(messages, fmt.Sprintf("Couldn't delete ns: %q (@cluster %d): %s (%#v)",
-						namespaceKey, clusterIdx, namespaceErr, namespaceErr))
-				}
-			}
-			log.Failf(strings.Join(messages, ","))
+		// Paranoia-- prevent reuse!
+		contextData.Namespace = ""
+		contextData.Clients.KubeClient = nil
+		contextData.namespacesToDelete = nil
+		contextData.projectsToDelete = nil
+	}
+
+	// if we had errors deleting, report them now.
+	for namespaceKey, namespaceErrors := range deleteionErrors {
+		for clusterIdx, namespaceErr := range namespaceErrors {
+			errs = append(errs, fmt.Errorf("Couldn't delete ns: %q (@cluster %d): %s (%#v)",
+				namespaceKey, clusterIdx, namespaceErr, namespaceErr))
 		}
-	}()
+	}
 
 	f.afterEachDone = true
+	return utilerrors.NewAggregate(errs)
 }
 
 // AfterSuite deletes the cluster level resources
 func (f *Framework) AfterSuite() {
+	err := f.AfterSuiteWithContext(gocontext.TODO())
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+}
+
+// AfterSuiteWithContext deletes the cluster level resources, returning
+// an error instead of failing the running spec
+func (f *Framework) AfterSuiteWithContext(ctx gocontext.Context) error {
 	// Remove cleanup action
 	RemoveCleanupAction(AfterSuite, f.cleanupHandleSuite)
 
+	// remove the tracked resources while operators are still
+	// available to process their finalizers
+	var errs []error
+	if err := f.forEachContext(func(ctxData *ContextData, logger *contextLogger) error {
+		if ctxData.Tracker == nil {
+			return nil
+		}
+		return ctxData.Tracker.deleteSuite(ctx)
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
 	// teardown suite
-	err := f.TeardownSuite()
-	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	if err := f.TeardownSuiteWithContext(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
 }
 
 func (f *Framework) TeardownEach() error {
+	return f.TeardownEachWithContext(gocontext.TODO())
+}
 
-	// Iterate through all contexts and deleting namespace related resources
-	for _, contextData := range f.ContextMap {
-		for _, operator := range contextData.OperatorMap {
+// TeardownEachWithContext tears down the namespace related resources of all operators,
+// running concurrently across contexts and operators and aggregating all errors found
+func (f *Framework) TeardownEachWithContext(ctx gocontext.Context) error {
+	return f.forEachContext(func(contextData *ContextData, logger *contextLogger) error {
+		return contextData.forEachOperator(func(operator operators.OperatorSetup) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			err := operator.TeardownEach()
 			if err != nil && !apierrors.IsNotFound(err) {
 				return fmt.Errorf("failed to teardown [each] operator [%s]: %v", operator.Name(), err)
 			}
-			log.Logf("%s teardown namespace [%s] successful", operator.Name(), contextData.Namespace)
-		}
-	}
-
-	return nil
+			logger.Logf("%s teardown namespace [%s] successful", operator.Name(), contextData.Namespace)
+			return nil
+		})
+	})
 }
 
 func (f *Framework) TeardownSuite() error {
+	return f.TeardownSuiteWithContext(gocontext.TODO())
+}
 
-	// Iterate through all contexts
-	for _, contextData := range f.ContextMap {
-		for _, operator := range contextData.OperatorMap {
+// TeardownSuiteWithContext deletes the operators from all contexts, running concurrently
+// across contexts and operators and aggregating all errors found
+func (f *Framework) TeardownSuiteWithContext(ctx gocontext.Context) error {
+	return f.forEachContext(func(contextData *ContextData, logger *contextLogger) error {
+		return contextData.forEachOperator(func(operator operators.OperatorSetup) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			err := operator.TeardownSuite()
 			if err != nil && !apierrors.IsNotFound(err) {
 				return fmt.Errorf("failed to delete operator [%s] from namespace [%s]: %v", operator.Name(), contextData.Namespace, err)
 			}
-			log.Logf("%s teardown suite successful on %s", operator.Name(), contextData.Namespace)
-		}
-	}
-
-	return nil
+			logger.Logf("%s teardown suite successful on %s", operator.Name(), contextData.Namespace)
+			return nil
+		})
+	})
 }
 
 func (f *Framework) Setup() error {
+	return f.SetupWithContext(gocontext.TODO())
+}
 
-	for _, ctxData := range f.ContextMap {
-		for _, operator := range ctxData.OperatorMap {
+// SetupWithContext sets up the operators and waits for them to be ready, running
+// concurrently across contexts and operators and aggregating all errors found
+func (f *Framework) SetupWithContext(ctx gocontext.Context) error {
+	return f.forEachContext(func(ctxData *ContextData, logger *contextLogger) error {
+		return ctxData.forEachOperator(func(operator operators.OperatorSetup) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			err := operator.Setup()
 			if err != nil {
 				return fmt.Errorf("failed to setup %s: %v", operator.Name(), err)
 			}
+			if f.fakeClients {
+				// simulated operators are ready as soon as set up
+				return nil
+			}
 			watchedNamespace := ctxData.Namespace
 			if f.globalOperatorFlag {
 				watchedNamespace = "openshift-operators"
 			}
-			err = WaitForDeployment(f.GetFirstContext().Clients.KubeClient, watchedNamespace, operator.Name(), 1, RetryInterval, Timeout)
+			err = WaitForDeploymentWithContext(ctx, ctxData.Clients.KubeClient, watchedNamespace, operator.Name(), 1, RetryInterval, Timeout)
 			if err != nil {
 				return fmt.Errorf("failed to wait for %s: %v", operator.Name(), err)
 			}
-		}
-	}
-	return nil
+			logger.Logf("%s is ready on %s", operator.Name(), watchedNamespace)
+			return nil
+		})
+	})
 }
 
-// GetFirstContext returns the first entry in the ContextMap or nil if none
+// GetFirstContext returns the ContextData of the first context given to the
+// framework (see Builder.WithContexts) or nil if none
 func (f *Framework) GetFirstContext() *ContextData {
+	for _, context := range f.contexts {
+		if cd, ok := f.ContextMap[context]; ok {
+			return cd
+		}
+	}
 	for _, cd := range f.ContextMap {
 		return cd
 	}
@@ -553,8 +775,41 @@ func (c *ContextData) IsOpenShift() bool {
 	return result
 }
 
+// GetConfig returns the rest.Config of the first context given to the framework
+// (or an empty one if no context has been set up yet). Multi-context tests must
+// use ContextData.GetConfig.
 func (f *Framework) GetConfig() rest.Config {
-	return f.restConfig
+	if ctxData := f.GetFirstContext(); ctxData != nil && ctxData.restConfig != nil {
+		return *ctxData.restConfig
+	}
+	return rest.Config{}
+}
+
+// GetConfig returns the rest.Config used to connect to this context's cluster
+func (c *ContextData) GetConfig() *rest.Config {
+	return c.restConfig
+}
+
+// GetRawConfig returns the kubeconfig this context has been loaded from
+func (c *ContextData) GetRawConfig() *clientcmdapi.Config {
+	return c.rawConfig
+}
+
+// GetKubeContext returns the kubeconfig context entry for this context,
+// or nil if not available
+func (c *ContextData) GetKubeContext() *clientcmdapi.Context {
+	if c.rawConfig == nil {
+		return nil
+	}
+	return c.rawConfig.Contexts[c.Id]
+}
+
+// spdyConfig returns the rest.Config to be used by SPDY based helpers
+func (c *ContextData) spdyConfig() (*rest.Config, error) {
+	if c.restConfig == nil {
+		return nil, fmt.Errorf("no rest config available for context %s", c.Id)
+	}
+	return c.restConfig, nil
 }
 
 func Int32Ptr(i int32) *int32 { return &i }
@@ -17,6 +17,7 @@ package framework
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -28,8 +29,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
@@ -49,6 +52,12 @@ func createTestProject(client projectv1.Interface, name string, labels map[strin
 }
 
 func createProject(client projectv1.Interface, name string, labels map[string]string) *openapiv1.Project {
+	project, err := createProjectWithContext(context.TODO(), client, name, labels)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Error creating project %v", project)
+	return project
+}
+
+func createProjectWithContext(ctx context.Context, client projectv1.Interface, name string, labels map[string]string) (*openapiv1.Project, error) {
 	projectObj := &openapiv1.Project{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   name,
@@ -56,9 +65,7 @@ func createProject(client projectv1.Interface, name string, labels map[string]st
 		},
 	}
 
-	project, err := client.ProjectV1().Projects().Create(context.TODO(), projectObj, metav1.CreateOptions{})
-	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Error creating project %v", project)
-	return project
+	return client.ProjectV1().Projects().Create(ctx, projectObj, metav1.CreateOptions{})
 }
 
 func (c *ContextData) CreateProject(clientSet *projectv1.Clientset,
@@ -68,6 +75,18 @@ func (c *ContextData) CreateProject(clientSet *projectv1.Clientset,
 	return ns
 }
 
+// CreateProjectWithContext creates a project for e2e testing, returning an error
+// instead of failing the spec.
+func (c *ContextData) CreateProjectWithContext(ctx context.Context, clientSet projectv1.Interface,
+	baseName string, labels map[string]string) (*openapiv1.Project, error) {
+	project, err := createProjectWithContext(ctx, clientSet, baseName, labels)
+	if err != nil {
+		return nil, fmt.Errorf("error creating project %s: %v", baseName, err)
+	}
+	c.AddProjectsToDelete(project)
+	return project, nil
+}
+
 func createTestNamespace(client clientset.Interface, name string, labels map[string]string) *corev1.Namespace {
 	ginkgo.By(fmt.Sprintf("Creating a namespace %s to execute the test in", name))
 	namespace := createNamespace(client, name, labels)
@@ -75,6 +94,12 @@ func createTestNamespace(client clientset.Interface, name string, labels map[str
 }
 
 func createNamespace(client clientset.Interface, name string, labels map[string]string) *corev1.Namespace {
+	namespace, err := createNamespaceWithContext(context.TODO(), client, name, labels)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Error creating namespace %v", name)
+	return namespace
+}
+
+func createNamespaceWithContext(ctx context.Context, client clientset.Interface, name string, labels map[string]string) (*corev1.Namespace, error) {
 	namespaceObj := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   name,
@@ -82,9 +107,7 @@ func createNamespace(client clientset.Interface, name string, labels map[string]
 		},
 	}
 
-	namespace, err := client.CoreV1().Namespaces().Create(context.TODO(), namespaceObj, metav1.CreateOptions{})
-	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Error creating namespace %v", namespaceObj)
-	return namespace
+	return client.CoreV1().Namespaces().Create(ctx, namespaceObj, metav1.CreateOptions{})
 }
 
 // CreateNamespace creates a namespace for e2e testing.
@@ -96,6 +119,19 @@ func (c *ContextData) CreateNamespace(clientSet *clientset.Clientset,
 	return ns
 }
 
+// CreateNamespaceWithContext creates a namespace for e2e testing, returning an error
+// instead of failing the spec.
+func (c *ContextData) CreateNamespaceWithContext(ctx context.Context, clientSet clientset.Interface,
+	baseName string, labels map[string]string) (*corev1.Namespace, error) {
+
+	ns, err := createNamespaceWithContext(ctx, clientSet, baseName, labels)
+	if err != nil {
+		return nil, fmt.Errorf("error creating namespace %s: %v", baseName, err)
+	}
+	c.AddNamespacesToDelete(ns)
+	return ns, nil
+}
+
 func (c *ContextData) AddProjectsToDelete(projects ...*openapiv1.Project) {
 	for _, proj := range projects {
 		if proj == nil {
@@ -115,6 +151,12 @@ func (c *ContextData) AddNamespacesToDelete(namespaces ...*corev1.Namespace) {
 }
 
 func generateProject(client projectv1.Interface, baseName string, labels map[string]string) *openapiv1.Project {
+	project, err := generateProjectWithContext(context.TODO(), client, baseName, labels)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Error generating project %v", baseName)
+	return project
+}
+
+func generateProjectWithContext(ctx context.Context, client projectv1.Interface, baseName string, labels map[string]string) (*openapiv1.Project, error) {
 	projectObj := &openapiv1.ProjectRequest{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: fmt.Sprintf("shipshape-%v-%v", baseName, util.String(5)),
@@ -122,12 +164,16 @@ func generateProject(client projectv1.Interface, baseName string, labels map[str
 		},
 	}
 
-	project, err := client.ProjectV1().ProjectRequests().Create(context.TODO(), projectObj, metav1.CreateOptions{})
-	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Error generating project %v", projectObj)
-	return project
+	return client.ProjectV1().ProjectRequests().Create(ctx, projectObj, metav1.CreateOptions{})
 }
 
 func generateNamespace(client clientset.Interface, baseName string, labels map[string]string) *corev1.Namespace {
+	namespace, err := generateNamespaceWithContext(context.TODO(), client, baseName, labels)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Error generating namespace %v", baseName)
+	return namespace
+}
+
+func generateNamespaceWithContext(ctx context.Context, client clientset.Interface, baseName string, labels map[string]string) (*corev1.Namespace, error) {
 	namespaceObj := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: fmt.Sprintf("shipshape-%v-", baseName),
@@ -135,14 +181,16 @@ func generateNamespace(client clientset.Interface, baseName string, labels map[s
 		},
 	}
 
-	namespace, err := client.CoreV1().Namespaces().Create(context.TODO(), namespaceObj, metav1.CreateOptions{})
-	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Error generating namespace %v", namespaceObj)
-	return namespace
+	return client.CoreV1().Namespaces().Create(ctx, namespaceObj, metav1.CreateOptions{})
 }
 
 // GenerateNamespace creates a namespace with a random name.
 func (c *ContextData) GenerateNamespace() (*corev1.Namespace, error) {
-	return c.Clients.KubeClient.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+	return c.GenerateNamespaceWithContext(context.TODO())
+}
+
+func (c *ContextData) GenerateNamespaceWithContext(ctx context.Context) (*corev1.Namespace, error) {
+	return c.Clients.KubeClient.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: namespaceNamePrefix,
 		},
@@ -150,38 +198,59 @@ func (c *ContextData) GenerateNamespace() (*corev1.Namespace, error) {
 }
 
 func (c *ContextData) GenerateProject() (*openapiv1.Project, error) {
-	return c.Clients.OcpClient.ProjectsClient.ProjectV1().Projects().Create(context.TODO(), &openapiv1.Project{
+	return c.GenerateProjectWithContext(context.TODO())
+}
+
+func (c *ContextData) GenerateProjectWithContext(ctx context.Context) (*openapiv1.Project, error) {
+	return c.Clients.OcpClient.ProjectsClient.ProjectV1().Projects().Create(ctx, &openapiv1.Project{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: namespaceNamePrefix,
 		},
 	}, metav1.CreateOptions{})
 }
 
-func deleteNamespace(client clientset.Interface, namespaceName string) error {
-
+// preserveNamespaces returns true if namespaces and projects must not be deleted,
+// either because DeleteNamespace is disabled or because the running spec has
+// failed and DeleteNamespaceOnFailure is disabled
+func preserveNamespaces() bool {
 	if !TestContext.DeleteNamespace {
 		log.Logf("Skipping as namespaces are meant to be preserved")
+		return true
+	}
+	if !TestContext.DeleteNamespaceOnFailure && ginkgo.CurrentGinkgoTestDescription().Failed {
+		log.Logf("Skipping as namespaces are meant to be preserved on failure")
+		return true
+	}
+	return false
+}
+
+func deleteNamespace(ctx context.Context, client clientset.Interface, namespaceName string) error {
+
+	if preserveNamespaces() {
 		return nil
 	}
 
-	return client.CoreV1().Namespaces().Delete(context.TODO(),
+	return client.CoreV1().Namespaces().Delete(ctx,
 		namespaceName,
 		metav1.DeleteOptions{})
 
 }
 
-func deleteProject(client projectv1.Interface, projectName string) error {
-	if !TestContext.DeleteNamespace {
-		log.Logf("Skipping removal as projects are meant to be preserved")
+func deleteProject(ctx context.Context, client projectv1.Interface, projectName string) error {
+	if preserveNamespaces() {
 		return nil
 	}
-	return client.ProjectV1().Projects().Delete(context.TODO(), projectName, metav1.DeleteOptions{})
+	return client.ProjectV1().Projects().Delete(ctx, projectName, metav1.DeleteOptions{})
 }
 
 func (c *ContextData) DeleteProject(prj *openapiv1.Project) []error {
+	return c.DeleteProjectWithContext(context.TODO(), prj)
+}
+
+func (c *ContextData) DeleteProjectWithContext(ctx context.Context, prj *openapiv1.Project) []error {
 	var errors []error
 
-	if err := deleteProject(c.Clients.OcpClient.ProjectsClient, prj.Name); err != nil {
+	if err := deleteProject(ctx, c.Clients.OcpClient.ProjectsClient, prj.Name); err != nil {
 		switch {
 		case apierrors.IsNotFound(err):
 			log.Logf("Namespace was already deleted")
@@ -197,9 +266,13 @@ func (c *ContextData) DeleteProject(prj *openapiv1.Project) []error {
 }
 
 func (c *ContextData) DeleteNamespace(ns *corev1.Namespace) []error {
+	return c.DeleteNamespaceWithContext(context.TODO(), ns)
+}
+
+func (c *ContextData) DeleteNamespaceWithContext(ctx context.Context, ns *corev1.Namespace) []error {
 	var errors []error
 
-	if err := deleteNamespace(c.Clients.KubeClient, ns.Name); err != nil {
+	if err := deleteNamespace(ctx, c.Clients.KubeClient, ns.Name); err != nil {
 		switch {
 		case apierrors.IsNotFound(err):
 			log.Logf("Namespace was already deleted")
@@ -219,9 +292,22 @@ func (c *ContextData) DeleteNamespace(ns *corev1.Namespace) []error {
 // Returns the list of deleted namespaces or an error.
 func DeleteNamespaces(c clientset.Interface, deleteFilter, skipFilter []string) ([]string, error) {
 	ginkgo.By("Deleting namespaces")
-	nsList, err := c.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
-	ExpectNoError(err, "Failed to get namespace list")
+	deleted, err := DeleteNamespacesWithContext(context.TODO(), c, deleteFilter, skipFilter)
+	ExpectNoError(err, "Failed to delete namespaces")
+	return deleted, nil
+}
+
+// DeleteNamespacesWithContext deletes all namespaces that match the given delete and skip filters,
+// returning the list of namespaces whose deletion has been requested. Errors deleting individual
+// namespaces are aggregated into the returned error.
+func DeleteNamespacesWithContext(ctx context.Context, c clientset.Interface, deleteFilter, skipFilter []string) ([]string, error) {
+	nsList, err := c.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace list: %v", err)
+	}
 	var deleted []string
+	var errs []error
+	var lock sync.Mutex
 	var wg sync.WaitGroup
 OUTER:
 	for _, item := range nsList.Items {
@@ -248,26 +334,34 @@ OUTER:
 		deleted = append(deleted, item.Name)
 		go func(nsName string) {
 			defer wg.Done()
-			defer ginkgo.GinkgoRecover()
-			gomega.Expect(c.CoreV1().Namespaces().Delete(context.TODO(), nsName, metav1.DeleteOptions{})).To(gomega.Succeed())
+			if err := c.CoreV1().Namespaces().Delete(ctx, nsName, metav1.DeleteOptions{}); err != nil {
+				lock.Lock()
+				errs = append(errs, fmt.Errorf("failed to delete namespace %s: %v", nsName, err))
+				lock.Unlock()
+				return
+			}
 			log.Logf("namespace : %v api call to delete is complete ", nsName)
 		}(item.Name)
 	}
 	wg.Wait()
-	return deleted, nil
+	return deleted, utilerrors.NewAggregate(errs)
 }
 
 // WaitForNamespacesDeleted waits for the namespaces to be deleted.
 func WaitForNamespacesDeleted(c clientset.Interface, namespaces []string, timeout time.Duration) error {
 	ginkgo.By("Waiting for namespaces to vanish")
+	return WaitForNamespacesDeletedWithContext(context.TODO(), c, namespaces, timeout)
+}
+
+func WaitForNamespacesDeletedWithContext(ctx context.Context, c clientset.Interface, namespaces []string, timeout time.Duration) error {
 	nsMap := map[string]bool{}
 	for _, ns := range namespaces {
 		nsMap[ns] = true
 	}
 
-	return wait.Poll(2*time.Second, timeout,
-		func() (bool, error) {
-			nsList, err := c.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	return wait.PollWithContext(ctx, 2*time.Second, timeout,
+		func(ctx context.Context) (bool, error) {
+			nsList, err := c.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 			if err != nil {
 				return false, err
 			}
@@ -279,3 +373,73 @@ func WaitForNamespacesDeleted(c clientset.Interface, namespaces []string, timeou
 			return true, nil
 		})
 }
+
+// CleanStart deletes all namespaces labelled with "e2e-framework" (left behind by
+// failed or interrupted runs) from all the given contexts, in case the --clean-start
+// flag has been set. If no contexts are given, the contexts from TestContext are used.
+func CleanStart(contexts ...string) {
+	if !TestContext.CleanStart {
+		return
+	}
+	ginkgo.By("Deleting namespaces left behind by previous runs")
+	ExpectNoError(CleanStartWithContext(context.TODO(), contexts...), "Failed to clean start")
+}
+
+// CleanStartWithContext deletes all namespaces labelled with "e2e-framework" from all
+// the given contexts (or the contexts from TestContext if none given) and waits for them
+// to vanish. Errors found on each context are aggregated into the returned error.
+func CleanStartWithContext(ctx context.Context, contexts ...string) error {
+	if len(contexts) == 0 {
+		var err error
+		if contexts, err = TestContext.LoadContexts(); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, kubeContext := range contexts {
+		if err := cleanStartContext(ctx, kubeContext); err != nil {
+			errs = append(errs, fmt.Errorf("context %s: %v", kubeContext, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func cleanStartContext(ctx context.Context, kubeContext string) error {
+	if len(TestContext.KubeConfig) == 0 {
+		TestContext.KubeConfig = os.Getenv(clientcmd.RecommendedConfigPathEnvVar)
+	}
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: TestContext.KubeConfig},
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("unable to load rest config: %v", err)
+	}
+	client, err := clientset.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	nsList, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: "e2e-framework"})
+	if err != nil {
+		return fmt.Errorf("failed to get namespace list: %v", err)
+	}
+	var namespaces []string
+	var errs []error
+	for _, ns := range nsList.Items {
+		if ns.DeletionTimestamp == nil {
+			log.Logf("Deleting namespace %s", ns.Name)
+			if err := client.CoreV1().Namespaces().Delete(ctx, ns.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete namespace %s: %v", ns.Name, err))
+				continue
+			}
+		}
+		namespaces = append(namespaces, ns.Name)
+	}
+	if len(namespaces) > 0 {
+		if err := WaitForNamespacesDeletedWithContext(ctx, client, namespaces, NamespaceCleanupTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("namespaces %v not deleted: %v", namespaces, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
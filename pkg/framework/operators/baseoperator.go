@@ -10,7 +10,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"regexp"
-	"strings"
 
 	"github.com/ghodss/yaml"
 	"github.com/rh-messaging/shipshape/pkg/framework/log"
@@ -24,7 +23,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	yamlserial "k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+	"k8s.io/apimachinery/pkg/types"
 	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
@@ -39,8 +40,18 @@ type dynamicAction int
 const (
 	dynamicActionCreate dynamicAction = iota
 	dynamicActionDelete
+	dynamicActionApply
+	// dynamicActionDeleteCreated deletes the resources, except for those that
+	// already existed when they were applied
+	dynamicActionDeleteCreated
+	// dynamicActionForceApply applies the resources taking over the fields managed
+	// by others, for the operator's own resources (like CRDs and RBAC)
+	dynamicActionForceApply
 )
 
+// FieldManager is the field manager used when applying resources server-side
+const FieldManager = "shipshape"
+
 // All the base operator stuff goes into this class. All operator-specific things go into specific classes.
 type BaseOperatorBuilder struct {
 	yamls           [][]byte
@@ -56,13 +67,16 @@ type BaseOperatorBuilder struct {
 	finalized       bool
 	crdsPrepared    bool
 	globalNamespace bool
+	olm             *OLMConfig
+	tracker         ResourceTracker
 }
 
 type BaseOperator struct {
 	restConfig        *rest.Config
 	rawConfig         *clientcmdapi.Config
-	kubeClient        *clientset.Clientset
+	kubeClient        clientset.Interface
 	extClient         *apiextension.Clientset
+	dynClient         dynamic.Interface
 	context           string
 	namespace         string
 	operatorInterface interface{}
@@ -85,6 +99,23 @@ type BaseOperator struct {
 	keepCRD           bool
 	crdsPrepared      bool
 	globalNamespace   bool
+	olm               *olmOperator
+	// existing holds the resources that already existed when applied,
+	// so that they are kept on teardown
+	existing map[string]bool
+	// extraEnv is appended to the environment of the operator container
+	extraEnv []corev1.EnvVar
+	// keepDeploymentName prevents renaming the deployment after the operator name
+	keepDeploymentName bool
+	// tracker records the resources created through CreateResourcesFromYAML
+	tracker ResourceTracker
+}
+
+// ResourceTracker records resources created on behalf of the running specs,
+// so that they can be removed once the specs (or the whole suite) complete
+type ResourceTracker interface {
+	Track(gvr schema.GroupVersionResource, namespace, name string)
+	TrackForSuite(gvr schema.GroupVersionResource, namespace, name string)
 }
 
 type DefinitionStruct struct {
@@ -148,6 +179,13 @@ func (b *BaseOperatorBuilder) WithYamlURLs(yamls []string) OperatorSetupBuilder
 	}
 }
 
+// WithResourceTracker records the resources created through CreateResourcesFromYAML
+// and CreateResourcesFromYAMLBytes into the given tracker
+func (b *BaseOperatorBuilder) WithResourceTracker(tracker ResourceTracker) OperatorSetupBuilder {
+	b.tracker = tracker
+	return b
+}
+
 // Pass loaded yamls objects instead of URLs
 func (b *BaseOperatorBuilder) WithYamls(yamls [][]byte) OperatorSetupBuilder {
 	b.yamls = yamls
@@ -208,6 +246,16 @@ func (b *BaseOperatorBuilder) SetOperatorName(operatorName string) OperatorSetup
 	return b
 }
 
+// OperatorName returns the name of the operator being built
+func (b *BaseOperatorBuilder) OperatorName() string {
+	return b.operatorName
+}
+
+// OperatorImage returns the image of the operator being built
+func (b *BaseOperatorBuilder) OperatorImage() string {
+	return b.image
+}
+
 func (b *BaseOperatorBuilder) Finalize() *BaseOperatorBuilder {
 	b.finalized = true
 	return b
@@ -236,6 +284,8 @@ func (b *BaseOperatorBuilder) Build() (OperatorSetup, error) {
 	baseOperator.customCommand = b.customCommand
 	baseOperator.crdsPrepared = b.crdsPrepared
 	baseOperator.globalNamespace = b.globalNamespace
+	baseOperator.olm = newOLMOperator(b.olm)
+	baseOperator.tracker = b.tracker
 	if err := baseOperator.Setup(); err != nil {
 		return nil, fmt.Errorf("failed to set up operator %s: %v", baseOperator.operatorName, err)
 	}
@@ -250,10 +300,13 @@ func (b *BaseOperator) InitFromBaseOperatorBuilder(builder *BaseOperatorBuilder)
 	b.namespace = builder.namespace
 	b.apiVersion = builder.apiVersion
 	b.operatorName = builder.operatorName
+	b.yamlURLs = builder.yamlURLs
 	b.yamls = builder.yamls
 	b.keepCRD = builder.keepCdrs
 	b.crdsPrepared = builder.crdsPrepared
 	b.globalNamespace = builder.globalNamespace
+	b.olm = newOLMOperator(builder.olm)
+	b.tracker = builder.tracker
 
 	// Initialize clients
 	if kubeClient, err := clientset.NewForConfig(b.restConfig); err != nil {
@@ -295,13 +348,40 @@ func (b *BaseOperator) errorItemLoad(failedType string, jsonObj []byte, parentEr
 	panic(fmt.Errorf("failed to load %s from json definition: %s %v", failedType, jsonObj, parentError))
 }
 
-func (b *BaseOperator) errorItemCreate(failedType string, parentError error) {
-	if strings.Contains(parentError.Error(), "already exists") {
-		//If any items already exist, don't remove those in teardown
-		b.keepCRD = true
-	} else {
+// existingKey identifies a resource recorded as pre-existing
+func existingKey(resource, namespace, name string) string {
+	return resource + "/" + namespace + "/" + name
+}
+
+// recordExisting records a resource that already existed when applied
+func (b *BaseOperator) recordExisting(resource, namespace, name string) {
+	if b.existing == nil {
+		b.existing = map[string]bool{}
+	}
+	b.existing[existingKey(resource, namespace, name)] = true
+}
+
+// isExisting returns true if the given resource already existed when applied,
+// in which case it must not be removed on teardown
+func (b *BaseOperator) isExisting(resource, namespace, name string) bool {
+	return b.existing[existingKey(resource, namespace, name)]
+}
+
+// errorItemCreate applies the given item if it already exists, so that resources
+// left behind by previous runs are updated to the expected definition (and kept on teardown),
+// even when their fields are managed by others
+func (b *BaseOperator) errorItemCreate(failedType string, item interface{}, parentError error) {
+	if !apierrors.IsAlreadyExists(parentError) {
 		panic(fmt.Errorf("failed to create %s : %v", failedType, parentError))
 	}
+	log.Logf("%s already exists, applying it", failedType)
+	jsonObj, err := json.Marshal(item)
+	if err == nil {
+		err = b.manageResourcesFromYAMLBytes(dynamicActionForceApply, jsonObj, nil)
+	}
+	if err != nil {
+		panic(fmt.Errorf("failed to apply %s : %v", failedType, err))
+	}
 }
 
 func (b *BaseOperator) setupServiceAccount(jsonObj []byte) {
@@ -310,7 +390,7 @@ func (b *BaseOperator) setupServiceAccount(jsonObj []byte) {
 		b.errorItemLoad("service account", jsonObj, err)
 	}
 	if _, err := b.kubeClient.CoreV1().ServiceAccounts(b.namespace).Create(context.TODO(), &b.serviceAccount, metav1.CreateOptions{}); err != nil {
-		b.errorItemCreate("service account", err)
+		b.errorItemCreate("service account", &b.serviceAccount, err)
 	}
 
 }
@@ -325,7 +405,7 @@ func (b *BaseOperator) setupRole(jsonObj []byte) {
 		log.Logf("Rule concerning %v is being created", item.Resources)
 	}
 	if _, err := b.kubeClient.RbacV1().Roles(b.namespace).Create(context.TODO(), &b.role, metav1.CreateOptions{}); err != nil {
-		b.errorItemCreate("role", err)
+		b.errorItemCreate("role", &b.role, err)
 	}
 }
 
@@ -334,9 +414,9 @@ func (b *BaseOperator) setupClusterRole(jsonObj []byte) {
 	if err := json.Unmarshal(jsonObj, &b.cRole); err != nil {
 		b.errorItemLoad("cluster role", jsonObj, err)
 	}
-	// Ignore errors if cluster level resource already exists
+	// Cluster level resources that already exist are updated
 	if _, err := b.kubeClient.RbacV1().ClusterRoles().Create(context.TODO(), &b.cRole, metav1.CreateOptions{}); err != nil {
-		b.errorItemCreate("cluster role", err)
+		b.errorItemCreate("cluster role", &b.cRole, err)
 	}
 }
 
@@ -347,7 +427,7 @@ func (b *BaseOperator) setupRoleBinding(jsonObj []byte) {
 	}
 	b.roleBinding.Name = "rolebinding-" + util.String(8) //silly.
 	if _, err := b.kubeClient.RbacV1().RoleBindings(b.namespace).Create(context.TODO(), &b.roleBinding, metav1.CreateOptions{}); err != nil {
-		b.errorItemCreate("role binding", err)
+		b.errorItemCreate("role binding", &b.roleBinding, err)
 	}
 }
 
@@ -358,17 +438,17 @@ func (b *BaseOperator) setupClusterRoleBinding(jsonObj []byte) {
 	}
 	b.cRoleBinding.Subjects[0].Namespace = "openshift-operators" //hardcoded as cluster-wide operator install is hardcoded to openshift-operators
 	if _, err := b.kubeClient.RbacV1().ClusterRoleBindings().Create(context.TODO(), &b.cRoleBinding, metav1.CreateOptions{}); err != nil {
-		b.errorItemCreate("cluster role binding", err)
+		b.errorItemCreate("cluster role binding", &b.cRoleBinding, err)
 	}
 }
 
 func (b *BaseOperator) setupConfigMap(jsonObj []byte) {
 	log.Logf("Setting up ConfigMap")
 	if err := json.Unmarshal(jsonObj, &b.configMap); err != nil {
-		b.errorItemLoad("cluster role binding", jsonObj, err)
+		b.errorItemLoad("config map", jsonObj, err)
 	}
 	if _, err := b.kubeClient.CoreV1().ConfigMaps(b.Namespace()).Create(context.TODO(), &b.configMap, metav1.CreateOptions{}); err != nil {
-		b.errorItemCreate("cluster role binding", err)
+		b.errorItemCreate("config map", &b.configMap, err)
 	}
 }
 
@@ -379,7 +459,8 @@ func (b *BaseOperator) setupCRD(json []byte) {
 		if err != nil {
 			b.errorItemLoad("CRD (json to yaml)", json, err)
 		}
-		if err := b.CreateResourcesFromYAMLBytes(yaml); err != nil && !strings.Contains(err.Error(), "already exists") {
+		// Applying, so that CRDs left by previous runs are updated to the expected version
+		if err := b.manageResourcesFromYAMLBytes(dynamicActionForceApply, yaml, nil); err != nil {
 			b.errorItemLoad("CRD", yaml, err)
 		}
 		b.crds = append(b.crds, yaml)
@@ -463,6 +544,16 @@ func (b *BaseOperator) setupDeployment(jsonItem []byte) {
 	if err := json.Unmarshal(jsonItem, &b.deploymentConfig); err != nil {
 		b.errorItemLoad("deployment", jsonItem, err)
 	}
+	b.customizeDeployment()
+
+	if err := b.CreateDeployment(); err != nil {
+		b.errorItemCreate("deployment", &b.deploymentConfig, err)
+	}
+}
+
+// customizeDeployment applies the image, command, name and global namespace
+// settings from the builder to the operator deployment
+func (b *BaseOperator) customizeDeployment() {
 	if b.image != "" {
 		//Customize the spec if that is requested
 		b.deploymentConfig.Spec.Template.Spec.Containers[0].Image = b.image
@@ -470,7 +561,11 @@ func (b *BaseOperator) setupDeployment(jsonItem []byte) {
 	if b.customCommand != "" {
 		b.deploymentConfig.Spec.Template.Spec.Containers[0].Command = []string{b.customCommand}
 	}
-	if b.operatorName != "" {
+	if len(b.extraEnv) > 0 {
+		container := &b.deploymentConfig.Spec.Template.Spec.Containers[0]
+		container.Env = append(container.Env, b.extraEnv...)
+	}
+	if b.operatorName != "" && !b.keepDeploymentName {
 		//b.deploymentConfig.Spec.Template.Spec.Containers[0].Name = b.operatorName
 		b.deploymentConfig.ObjectMeta.Name = b.operatorName
 	}
@@ -489,10 +584,6 @@ func (b *BaseOperator) setupDeployment(jsonItem []byte) {
 			}
 		} */ // - may be better to overwrite value in env instead of appending new envvar, unssure yet
 	}
-
-	if err := b.CreateDeployment(); err != nil {
-		b.errorItemCreate("deployment", err)
-	}
 }
 
 func (b *BaseOperator) Namespace() string {
@@ -524,6 +615,9 @@ func (b *BaseOperator) APIVersion() string {
 }
 
 func (b *BaseOperator) Setup() error {
+	if b.IsOLM() {
+		return b.SetupOLM()
+	}
 	if err := b.SetupYamls(); err != nil {
 		return err
 	}
@@ -559,39 +653,59 @@ func (b *BaseOperator) CreateDeployment() error {
 }
 
 func (b *BaseOperator) TeardownEach() error {
+	if b.IsOLM() {
+		return b.TeardownEachOLM()
+	}
 	if b.keepCRD {
 		return nil
-	} else {
-		err := b.kubeClient.CoreV1().
+	}
+	return b.deleteNamespacedResources()
+}
+
+// deleteNamespacedResources removes the service account, role, role binding and
+// deployment of the operator, keeping those that already existed before the setup
+func (b *BaseOperator) deleteNamespacedResources() error {
+	var err error
+	if !b.isExisting("serviceaccounts", b.namespace, b.serviceAccount.Name) {
+		err = b.kubeClient.CoreV1().
 			ServiceAccounts(b.namespace).
 			Delete(context.TODO(), b.serviceAccount.Name, metav1.DeleteOptions{})
 		if err != nil && !apierrors.IsNotFound(err) {
 			return err
 		}
+	}
+	if !b.isExisting("roles", b.namespace, b.role.Name) {
 		err = b.kubeClient.RbacV1().
 			Roles(b.namespace).
 			Delete(context.TODO(), b.role.Name, metav1.DeleteOptions{})
 		if err != nil && !apierrors.IsNotFound(err) {
 			return err
 		}
+	}
+	if !b.isExisting("rolebindings", b.namespace, b.roleBinding.Name) {
 		err = b.kubeClient.RbacV1().
 			RoleBindings(b.namespace).
 			Delete(context.TODO(), b.roleBinding.Name, metav1.DeleteOptions{})
 		if err != nil && !apierrors.IsNotFound(err) {
 			return err
 		}
+	}
+	if !b.isExisting("deployments", b.namespace, b.deploymentConfig.Name) {
 		err = b.kubeClient.AppsV1().
 			Deployments(b.namespace).
 			Delete(context.TODO(), b.deploymentConfig.Name, metav1.DeleteOptions{})
 		if err != nil && !apierrors.IsNotFound(err) {
 			return err
 		}
-		log.Logf("%s teradown namespace succesful", b.namespace)
-		return nil
 	}
+	log.Logf("%s teradown namespace succesful", b.namespace)
+	return nil
 }
 
 func (b *BaseOperator) TeardownSuite() error {
+	if b.IsOLM() {
+		return b.TeardownSuiteOLM()
+	}
 	if b.keepCRD {
 		return nil
 	} else {
@@ -601,7 +715,7 @@ func (b *BaseOperator) TeardownSuite() error {
 		}
 
 		for _, crd := range b.crds {
-			err = b.DeleteResourcesFromYAMLBytes(crd)
+			err = b.manageResourcesFromYAMLBytes(dynamicActionDeleteCreated, crd, nil)
 			if err != nil && !apierrors.IsNotFound(err) {
 				return err
 			}
@@ -609,11 +723,14 @@ func (b *BaseOperator) TeardownSuite() error {
 		return nil
 	}
 }
-func (b *BaseOperator) manageResourcesFromYAMLBytes(action dynamicAction, yamlData []byte) error {
+
+// manageResourcesFromYAMLBytes runs the given action against all resources from the
+// provided YAML, recording the created ones into the given tracker (if not nil)
+func (b *BaseOperator) manageResourcesFromYAMLBytes(action dynamicAction, yamlData []byte, tracker ResourceTracker) error {
 	var err error
 
 	// Creating a dynamic client
-	dynClient, err := dynamic.NewForConfig(b.restConfig)
+	dynClient, err := b.dynamicClient()
 	if err != nil {
 		return fmt.Errorf("error creating a dynamic k8s client: %s", err)
 	}
@@ -670,6 +787,7 @@ func (b *BaseOperator) manageResourcesFromYAMLBytes(action dynamicAction, yamlDa
 		}
 		// Creating the dynamic resource
 		errorAction := ""
+		track := tracker != nil && action != dynamicActionDelete && action != dynamicActionDeleteCreated
 		switch action {
 		case dynamicActionCreate:
 			_, err = k8sResource.Create(context.TODO(), unstructuredObj, metav1.CreateOptions{})
@@ -677,45 +795,99 @@ func (b *BaseOperator) manageResourcesFromYAMLBytes(action dynamicAction, yamlDa
 		case dynamicActionDelete:
 			err = k8sResource.Delete(context.TODO(), unstructuredObj.GetName(), metav1.DeleteOptions{})
 			errorAction = "deleting"
+		case dynamicActionDeleteCreated:
+			if b.isExisting(mapping.Resource.Resource, unstructuredObj.GetNamespace(), unstructuredObj.GetName()) {
+				log.Logf("keeping %s %s as it already existed", gvk.Kind, unstructuredObj.GetName())
+				continue
+			}
+			err = k8sResource.Delete(context.TODO(), unstructuredObj.GetName(), metav1.DeleteOptions{})
+			errorAction = "deleting"
+		case dynamicActionApply, dynamicActionForceApply:
+			errorAction = "applying"
+			// Resources that already exist are kept on teardown, the
+			// ones that did not exist yet are the only ones to be tracked
+			_, err = k8sResource.Get(context.TODO(), unstructuredObj.GetName(), metav1.GetOptions{})
+			existing := err == nil
+			if err != nil && !apierrors.IsNotFound(err) {
+				break
+			}
+			if existing {
+				b.recordExisting(mapping.Resource.Resource, unstructuredObj.GetNamespace(), unstructuredObj.GetName())
+			}
+			track = track && !existing
+			// Unless forced, fields managed by others (like OLM) are reported as conflicts
+			force := action == dynamicActionForceApply
+			var data []byte
+			if data, err = unstructuredObj.MarshalJSON(); err == nil {
+				_, err = k8sResource.Patch(context.TODO(), unstructuredObj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+					FieldManager: FieldManager,
+					Force:        &force,
+				})
+			}
 		}
 		if err != nil {
 			return fmt.Errorf("error %s resource [group=%s - kind=%s] - %s", errorAction, gvk.Group, gvk.Kind, err)
 		}
+		if track && mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			tracker.Track(mapping.Resource, unstructuredObj.GetNamespace(), unstructuredObj.GetName())
+		} else if track {
+			// Cluster scoped resources (like CRDs) are shared by all specs of the suite
+			tracker.TrackForSuite(mapping.Resource, "", unstructuredObj.GetName())
+		}
 	}
-
-	return err
 }
 
+// CreateResourcesFromYAMLBytes creates all resources from the provided YAML,
+// recording them into the resource tracker (if one has been set)
 func (b *BaseOperator) CreateResourcesFromYAMLBytes(yamlData []byte) error {
-	return b.manageResourcesFromYAMLBytes(dynamicActionCreate, yamlData)
+	return b.manageResourcesFromYAMLBytes(dynamicActionCreate, yamlData, b.tracker)
 }
 
 func (b *BaseOperator) DeleteResourcesFromYAMLBytes(yamlData []byte) error {
-	return b.manageResourcesFromYAMLBytes(dynamicActionDelete, yamlData)
+	return b.manageResourcesFromYAMLBytes(dynamicActionDelete, yamlData, nil)
+}
+
+// ApplyResourcesFromYAMLBytes applies all resources from the provided YAML server-side,
+// using the shipshape field manager, so that existing resources are updated to match it.
+// Existing resources whose fields are managed by others are reported as conflicts.
+// Resources that did not exist are recorded into the resource tracker (if one has been set).
+func (b *BaseOperator) ApplyResourcesFromYAMLBytes(yamlData []byte) error {
+	return b.manageResourcesFromYAMLBytes(dynamicActionApply, yamlData, b.tracker)
 }
 
 // CreateResourcesFromYAML creates all resources from the provided YAML file
 // or URL using an initialized VanClient instance.
 func (b *BaseOperator) CreateResourcesFromYAML(fileOrUrl string) error {
-	var yamlData []byte
-	var err error
+	yamlData, err := readYAML(fileOrUrl)
+	if err != nil {
+		return err
+	}
+	return b.CreateResourcesFromYAMLBytes(yamlData)
+}
 
+// ApplyResourcesFromYAML applies all resources from the provided YAML file
+// or URL server-side (see ApplyResourcesFromYAMLBytes)
+func (b *BaseOperator) ApplyResourcesFromYAML(fileOrUrl string) error {
+	yamlData, err := readYAML(fileOrUrl)
+	if err != nil {
+		return err
+	}
+	return b.ApplyResourcesFromYAMLBytes(yamlData)
+}
+
+// readYAML returns the content for the provided YAML file or URL
+func readYAML(fileOrUrl string) ([]byte, error) {
 	// Load YAML from an http/https url or local file
 	isUrl, _ := regexp.Compile("http[s]*://")
 	if isUrl.MatchString(fileOrUrl) {
-		yamlData, err = readYAMLFromUrl(fileOrUrl)
-		if err != nil {
-			return err
-		}
-	} else {
-		// Read YAML file
-		yamlData, err = ioutil.ReadFile(fileOrUrl)
-		if err != nil {
-			return fmt.Errorf("error reading yaml file: %s", err)
-		}
+		return readYAMLFromUrl(fileOrUrl)
 	}
-
-	return b.CreateResourcesFromYAMLBytes(yamlData)
+	// Read YAML file
+	yamlData, err := ioutil.ReadFile(fileOrUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error reading yaml file: %s", err)
+	}
+	return yamlData, nil
 }
 
 // readYAMLFromUrl returns the content for the provided url
@@ -755,14 +927,17 @@ func readYAMLIgnoringComments(yamlData []byte) (bytes.Buffer, error) {
 	yamlStarted := false
 	for {
 		line, err := yamlBufReader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return yamlNoComments, err
+		}
+		// The last line may not be terminated (like json marshalled objects)
+		if line != "" && (yamlStarted || !ignoreRegexp.MatchString(line)) {
+			yamlStarted = true
+			_, _ = yamlBufWriter.WriteString(line)
+		}
 		if err == io.EOF {
 			break
 		}
-		if !yamlStarted && ignoreRegexp.MatchString(line) {
-			continue
-		}
-		yamlStarted = true
-		_, _ = yamlBufWriter.WriteString(line)
 	}
 	_ = yamlBufWriter.Flush()
 	return yamlNoComments, nil
@@ -0,0 +1,222 @@
+package operators
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// fakeTracker records the resources tracked for each spec and for the suite
+type fakeTracker struct {
+	each, suite []string
+}
+
+func (t *fakeTracker) Track(gvr schema.GroupVersionResource, namespace, name string) {
+	t.each = append(t.each, gvr.Resource+"/"+name)
+}
+
+func (t *fakeTracker) TrackForSuite(gvr schema.GroupVersionResource, namespace, name string) {
+	t.suite = append(t.suite, gvr.Resource+"/"+name)
+}
+
+// TestTrackResourcesByScope validates that namespaced resources are tracked for
+// each spec, while cluster scoped ones are tracked for the whole suite
+func TestTrackResourcesByScope(t *testing.T) {
+	b, _, _ := newFakeOperator(nil)
+	tracker := &fakeTracker{}
+	b.tracker = tracker
+
+	err := b.ApplyResourcesFromYAMLBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test-config
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: tests.example.com
+`))
+	if err != nil {
+		t.Fatalf("unexpected error applying: %v", err)
+	}
+	if len(tracker.each) != 1 || tracker.each[0] != "configmaps/test-config" {
+		t.Errorf("unexpected resources tracked for each spec: %v", tracker.each)
+	}
+	if len(tracker.suite) != 1 || tracker.suite[0] != "customresourcedefinitions/tests.example.com" {
+		t.Errorf("unexpected resources tracked for the suite: %v", tracker.suite)
+	}
+}
+
+func newCRDJSON(name string) []byte {
+	return []byte(`{"apiVersion":"apiextensions.k8s.io/v1","kind":"CustomResourceDefinition","metadata":{"name":"` + name + `"}}`)
+}
+
+// TestTeardownKeepsExisting validates that CRDs which already existed when applied
+// are kept on teardown, while the ones created by the setup are removed
+func TestTeardownKeepsExisting(t *testing.T) {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("existing.example.com")
+	b, _, dynClient := newFakeOperator(nil, crd)
+
+	b.setupCRD(newCRDJSON("existing.example.com"))
+	b.setupCRD(newCRDJSON("created.example.com"))
+	if !exists(t, dynClient, crdGVR, "", "created.example.com") {
+		t.Fatalf("crd not created")
+	}
+
+	if err := b.TeardownSuite(); err != nil {
+		t.Fatalf("unexpected error tearing down: %v", err)
+	}
+	if !exists(t, dynClient, crdGVR, "", "existing.example.com") {
+		t.Errorf("existing crd removed")
+	}
+	if exists(t, dynClient, crdGVR, "", "created.example.com") {
+		t.Errorf("created crd not removed")
+	}
+}
+
+// conflictingClient rejects applying cluster scoped resources that already exist
+// without forcing, as the API server does when their fields are managed by others
+type conflictingClient struct {
+	dynamic.Interface
+}
+
+func (c conflictingClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return conflictingResource{c.Interface.Resource(gvr), gvr}
+}
+
+type conflictingResource struct {
+	dynamic.NamespaceableResourceInterface
+	gvr schema.GroupVersionResource
+}
+
+func (r conflictingResource) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if _, err := r.Get(ctx, name, metav1.GetOptions{}); err == nil && pt == types.ApplyPatchType && (options.Force == nil || !*options.Force) {
+		return nil, apierrors.NewConflict(r.gvr.GroupResource(), name, fmt.Errorf("conflict with \"olm\""))
+	}
+	return r.NamespaceableResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+}
+
+// TestApplyConflicts validates that the operator's own CRDs are applied over fields
+// managed by others, while other applied resources report the conflicts
+func TestApplyConflicts(t *testing.T) {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("existing.example.com")
+	crd.SetLabels(map[string]string{"version": "1.0"})
+	b, _, dynClient := newFakeOperator(nil, crd)
+	b.dynClient = conflictingClient{dynClient}
+
+	if err := b.ApplyResourcesFromYAMLBytes(newCRDJSON("existing.example.com")); err == nil || !strings.Contains(err.Error(), "conflict") {
+		t.Errorf("expected conflict error, got: %v", err)
+	}
+
+	b.setupCRD([]byte(`{"apiVersion":"apiextensions.k8s.io/v1","kind":"CustomResourceDefinition","metadata":{"name":"existing.example.com","labels":{"version":"2.0"}}}`))
+	current, err := dynClient.Resource(crdGVR).Get(context.Background(), "existing.example.com", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get crd: %v", err)
+	}
+	if version := current.GetLabels()["version"]; version != "2.0" {
+		t.Errorf("existing crd not updated, version: %q", version)
+	}
+}
+
+// TestSetupExistingItem validates that items which already exist are applied
+// (with their definitions in json) and recorded, so that they are kept on teardown
+func TestSetupExistingItem(t *testing.T) {
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "upgrade-test"}}
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetNamespace(existing.Namespace)
+	u.SetName(existing.Name)
+	b, _, dynClient := newFakeOperator([]runtime.Object{existing}, u)
+
+	b.setupConfigMap([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"test-config"},"data":{"version":"2.0"}}`))
+	if !b.isExisting("configmaps", "upgrade-test", "test-config") {
+		t.Errorf("existing config map not recorded")
+	}
+	configMap, err := dynClient.Resource(configMapGVR).Namespace("upgrade-test").Get(context.Background(), "test-config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get config map: %v", err)
+	}
+	if version, _, _ := unstructured.NestedString(configMap.Object, "data", "version"); version != "2.0" {
+		t.Errorf("existing config map not applied, version: %q", version)
+	}
+}
+
+// TestQdrTeardownKeepsExisting validates that the qdr operator keeps the namespaced
+// resources which already existed when set up, as CRDs are kept by default
+func TestQdrTeardownKeepsExisting(t *testing.T) {
+	existing := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "qdr-operator", Namespace: "upgrade-test"}}
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ServiceAccount")
+	u.SetNamespace(existing.Namespace)
+	u.SetName(existing.Name)
+	b, kubeClient, _ := newFakeOperator([]runtime.Object{existing}, u)
+	q := &QdrOperator{BaseOperator: *b}
+	q.keepCRD = true
+
+	q.setupServiceAccount([]byte(`{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"qdr-operator"}}`))
+	q.setupRole([]byte(`{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"Role","metadata":{"name":"qdr-operator"}}`))
+	if err := q.TeardownEach(); err != nil {
+		t.Fatalf("unexpected error tearing down: %v", err)
+	}
+	if _, err := kubeClient.CoreV1().ServiceAccounts("upgrade-test").Get(context.Background(), "qdr-operator", metav1.GetOptions{}); err != nil {
+		t.Errorf("existing service account removed: %v", err)
+	}
+	if _, err := kubeClient.RbacV1().Roles("upgrade-test").Get(context.Background(), "qdr-operator", metav1.GetOptions{}); err == nil {
+		t.Errorf("created role not removed")
+	}
+}
+
+// TestBuildWithYamlURLs validates that the yaml URLs given to the builders
+// replace the default ones of the built operators
+func TestBuildWithYamlURLs(t *testing.T) {
+	urls := []string{"https://example.com/deploy/operator.yaml"}
+	for _, operatorType := range []OperatorType{OperatorTypeBroker, OperatorTypeQdr} {
+		builder, err := NewOperatorBuilder(operatorType)
+		if err != nil {
+			t.Fatalf("unable to create builder for %s: %v", operatorType, err)
+		}
+		// the builder methods return the embedded base builder, so the
+		// original one is used to build the operator
+		restConfig := &rest.Config{
+			Host:          "https://127.0.0.1:6443",
+			ContentConfig: rest.ContentConfig{NegotiatedSerializer: scheme.Codecs.WithoutConversion()},
+		}
+		builder.NewBuilder(restConfig, nil).WithYamlURLs(urls)
+		operator, err := builder.Build()
+		if err != nil {
+			t.Fatalf("unable to build %s: %v", operatorType, err)
+		}
+		var yamlURLs []string
+		switch o := operator.(type) {
+		case *BrokerOperator:
+			yamlURLs = o.yamlURLs
+		case *QdrOperator:
+			yamlURLs = o.yamlURLs
+		}
+		if !reflect.DeepEqual(yamlURLs, urls) {
+			t.Errorf("%s: unexpected yaml URLs: %v", operatorType, yamlURLs)
+		}
+	}
+}
@@ -31,7 +31,7 @@ func (b *BrokerOperatorBuilder) Build() (OperatorSetup, error) {
 
 	broker.customCommand = b.customCommand
 	// Setting up the defaults
-	if broker.yamls != nil {
+	if broker.IsOLM() || broker.yamls != nil {
 
 	} else if broker.yamlURLs == nil {
 		baseImportPath := "https://raw.githubusercontent.com/artemiscloud/activemq-artemis-operator/main/deploy/"
@@ -64,6 +64,9 @@ func (b *BrokerOperator) Namespace() string {
 }
 
 func (b *BrokerOperator) Setup() error {
+	if b.IsOLM() {
+		return b.SetupOLM()
+	}
 	log.Logf("Setting up from YAMLs (brokeroperator)")
 	if err := b.SetupYamls(); err != nil {
 		return err
@@ -72,6 +75,9 @@ func (b *BrokerOperator) Setup() error {
 }
 
 func (b *BrokerOperator) TeardownEach() error {
+	if b.IsOLM() {
+		return b.TeardownEachOLM()
+	}
 	log.Logf("deliting operator from %s", b.Namespace())
 	err := b.kubeClient.CoreV1().ServiceAccounts(b.Namespace()).Delete(context.TODO(), b.Name(), metav1.DeleteOptions{})
 	if err != nil && !apierrors.IsNotFound(err) {
@@ -95,20 +101,32 @@ func (b *BrokerOperator) TeardownEach() error {
 }
 
 func (b *BrokerOperator) TeardownSuite() error {
-	// If CRD Was found prior to setup, keep cluster level resources
+	if b.IsOLM() {
+		return b.TeardownSuiteOLM()
+	}
+	// Keep cluster level resources if requested through KeepCdr
 	if b.keepCRD {
 		return nil
 	}
 
-	err := b.kubeClient.RbacV1().ClusterRoles().Delete(context.TODO(), b.Name(), metav1.DeleteOptions{})
-	if err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("failed to delete %s cluster role: %v", b.Name(), err)
+	// Cluster level resources that already existed before the setup are kept
+	var err error
+	if !b.isExisting("clusterroles", "", b.Name()) {
+		err = b.kubeClient.RbacV1().ClusterRoles().Delete(context.TODO(), b.Name(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s cluster role: %v", b.Name(), err)
+		}
 	}
-	err = b.kubeClient.RbacV1().ClusterRoleBindings().Delete(context.TODO(), b.Name(), metav1.DeleteOptions{})
-	if err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("failed to delete %s cluster role binding: %v", b.Name(), err)
+	if !b.isExisting("clusterrolebindings", "", b.Name()) {
+		err = b.kubeClient.RbacV1().ClusterRoleBindings().Delete(context.TODO(), b.Name(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s cluster role binding: %v", b.Name(), err)
+		}
 	}
 	for _, crdName := range b.CRDNames() {
+		if b.isExisting("customresourcedefinitions", "", crdName) {
+			continue
+		}
 		err = b.extClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(context.TODO(), crdName, metav1.DeleteOptions{})
 		if err != nil && !apierrors.IsNotFound(err) {
 			return fmt.Errorf("failed to delete %s crd: %v", b.Name(), err)
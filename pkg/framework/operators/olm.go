@@ -0,0 +1,413 @@
+package operators
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	olmGroup                     = "operators.coreos.com"
+	olmOpenShiftCatalogNamespace = "openshift-marketplace"
+	olmCatalogNamespace          = "olm"
+	olmRetryInterval             = 5 * time.Second
+)
+
+var (
+	// OLMTimeout is the maximum amount of time to wait for an operator
+	// installed through OLM to be ready (or removed)
+	OLMTimeout = 10 * time.Minute
+
+	catalogSourceGVR = schema.GroupVersionResource{Group: olmGroup, Version: "v1alpha1", Resource: "catalogsources"}
+	operatorGroupGVR = schema.GroupVersionResource{Group: olmGroup, Version: "v1", Resource: "operatorgroups"}
+	subscriptionGVR  = schema.GroupVersionResource{Group: olmGroup, Version: "v1alpha1", Resource: "subscriptions"}
+	csvGVR           = schema.GroupVersionResource{Group: olmGroup, Version: "v1alpha1", Resource: "clusterserviceversions"}
+	installPlanGVR   = schema.GroupVersionResource{Group: olmGroup, Version: "v1alpha1", Resource: "installplans"}
+	crdGVR           = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+// InstallPlanApproval defines how install plans of an OLM subscription are approved
+type InstallPlanApproval string
+
+const (
+	InstallPlanApprovalAutomatic InstallPlanApproval = "Automatic"
+	InstallPlanApprovalManual    InstallPlanApproval = "Manual"
+)
+
+// OLMConfig describes how an operator must be installed through the
+// Operator Lifecycle Manager, instead of creating its resources from YAMLs
+type OLMConfig struct {
+	// Package is the name of the operator package in the catalog
+	Package string
+	// Channel to subscribe to (the package default channel is used if empty)
+	Channel string
+	// StartingCSV is the ClusterServiceVersion to install (latest from channel if empty)
+	StartingCSV string
+	// CatalogSource is the name of the catalog providing the package
+	CatalogSource string
+	// CatalogNamespace is the namespace of the CatalogSource (defaults to
+	// openshift-marketplace if available or olm otherwise)
+	CatalogNamespace string
+	// CatalogImage is the index image to create the CatalogSource from. If empty,
+	// CatalogSource must refer to an existing catalog.
+	CatalogImage string
+	// InstallPlanApproval defaults to Automatic. When Manual, the install plan
+	// for the StartingCSV is approved during setup.
+	InstallPlanApproval InstallPlanApproval
+}
+
+// olmOperator holds the state of an operator installation made through OLM
+type olmOperator struct {
+	config               OLMConfig
+	catalogCreated       bool
+	operatorGroupCreated bool
+	installedCSV         string
+	// ownedCRDs are the CRDs owned by the installed CSVs, recorded when they
+	// succeed, as the CSVs are removed on each teardown
+	ownedCRDs []string
+}
+
+func newOLMOperator(config *OLMConfig) *olmOperator {
+	if config == nil {
+		return nil
+	}
+	return &olmOperator{config: *config}
+}
+
+// WithOLM installs the operator through OLM, using the given package, channel and
+// catalog, instead of creating the operator resources from YAMLs. The operator name
+// must match the name of the deployment defined by the CSV.
+func (b *BaseOperatorBuilder) WithOLM(config OLMConfig) OperatorSetupBuilder {
+	if !b.finalized {
+		b.olm = &config
+		return b
+	} else {
+		panic(fmt.Errorf("can't edit operator builder post-finalization"))
+	}
+}
+
+// IsOLM returns true if the operator is installed through OLM
+func (b *BaseOperator) IsOLM() bool {
+	return b.olm != nil
+}
+
+// InstalledCSV returns the name of the ClusterServiceVersion installed through OLM
+func (b *BaseOperator) InstalledCSV() string {
+	if b.olm == nil {
+		return ""
+	}
+	return b.olm.installedCSV
+}
+
+func (b *BaseOperator) dynamicClient() (dynamic.Interface, error) {
+	if b.dynClient == nil {
+		client, err := dynamic.NewForConfig(b.restConfig)
+		if err != nil {
+			return nil, err
+		}
+		b.dynClient = client
+	}
+	return b.dynClient, nil
+}
+
+// catalogNamespace returns the namespace of the CatalogSource to be used
+func (b *BaseOperator) catalogNamespace(ctx context.Context) string {
+	if b.olm.config.CatalogNamespace == "" {
+		b.olm.config.CatalogNamespace = olmCatalogNamespace
+		if _, err := b.kubeClient.CoreV1().Namespaces().Get(ctx, olmOpenShiftCatalogNamespace, metav1.GetOptions{}); err == nil {
+			b.olm.config.CatalogNamespace = olmOpenShiftCatalogNamespace
+		}
+	}
+	return b.olm.config.CatalogNamespace
+}
+
+func (b *BaseOperator) SetupOLM() error {
+	return b.SetupOLMWithContext(context.TODO())
+}
+
+// SetupOLMWithContext creates the CatalogSource (if an image is given), the OperatorGroup
+// (if the namespace does not have one yet) and the Subscription, waiting for the CSV to
+// succeed till OLMTimeout expires or the given context is done
+func (b *BaseOperator) SetupOLMWithContext(ctx context.Context) error {
+	config := b.olm.config
+	if config.Package == "" || config.CatalogSource == "" {
+		return fmt.Errorf("package and catalog source must be provided to install through OLM")
+	}
+	if config.InstallPlanApproval == "" {
+		config.InstallPlanApproval = InstallPlanApprovalAutomatic
+	}
+
+	client, err := b.dynamicClient()
+	if err != nil {
+		return err
+	}
+	catalogNamespace := b.catalogNamespace(ctx)
+
+	// CatalogSource
+	if config.CatalogImage != "" {
+		log.Logf("Creating catalog source %s/%s from %s", catalogNamespace, config.CatalogSource, config.CatalogImage)
+		catalog := newUnstructured(catalogSourceGVR, "CatalogSource", catalogNamespace, config.CatalogSource, map[string]interface{}{
+			"sourceType":  "grpc",
+			"image":       config.CatalogImage,
+			"displayName": config.CatalogSource,
+		})
+		_, err = client.Resource(catalogSourceGVR).Namespace(catalogNamespace).Create(ctx, catalog, metav1.CreateOptions{})
+		switch {
+		case err == nil:
+			b.olm.catalogCreated = true
+		case apierrors.IsAlreadyExists(err):
+			log.Logf("Catalog source %s already exists", config.CatalogSource)
+		default:
+			return fmt.Errorf("failed to create catalog source %s: %v", config.CatalogSource, err)
+		}
+	}
+
+	// OperatorGroup (only one is allowed per namespace)
+	groups, err := client.Resource(operatorGroupGVR).Namespace(b.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list operator groups in %s: %v", b.namespace, err)
+	}
+	if len(groups.Items) == 0 {
+		spec := map[string]interface{}{}
+		if !b.globalNamespace {
+			spec["targetNamespaces"] = []interface{}{b.namespace}
+		}
+		log.Logf("Creating operator group %s/%s", b.namespace, b.operatorName)
+		group := newUnstructured(operatorGroupGVR, "OperatorGroup", b.namespace, b.operatorName, spec)
+		if _, err = client.Resource(operatorGroupGVR).Namespace(b.namespace).Create(ctx, group, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create operator group %s: %v", b.operatorName, err)
+		}
+		b.olm.operatorGroupCreated = true
+	}
+
+	// Subscription
+	spec := map[string]interface{}{
+		"name":                config.Package,
+		"source":              config.CatalogSource,
+		"sourceNamespace":     catalogNamespace,
+		"installPlanApproval": string(config.InstallPlanApproval),
+	}
+	if config.Channel != "" {
+		spec["channel"] = config.Channel
+	}
+	if config.StartingCSV != "" {
+		spec["startingCSV"] = config.StartingCSV
+	}
+	log.Logf("Subscribing to %s (channel: %q, csv: %q) in %s", config.Package, config.Channel, config.StartingCSV, b.namespace)
+	subscription := newUnstructured(subscriptionGVR, "Subscription", b.namespace, config.Package, spec)
+	if _, err = client.Resource(subscriptionGVR).Namespace(b.namespace).Create(ctx, subscription, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create subscription %s: %v", config.Package, err)
+	}
+
+	csv, err := b.waitForSubscriptionCSV(ctx, client, config.StartingCSV, "", config.InstallPlanApproval == InstallPlanApprovalManual)
+	if err != nil {
+		return err
+	}
+	return b.waitForCSV(ctx, client, csv)
+}
+
+// waitForSubscriptionCSV waits for the subscription to report the CSV being installed
+// (matching the expected one, if given, and different from the previous one, if given),
+// approving its install plan if requested
+func (b *BaseOperator) waitForSubscriptionCSV(ctx context.Context, client dynamic.Interface, expectedCSV, previousCSV string, approve bool) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, OLMTimeout)
+	defer cancel()
+
+	var csv string
+	err := wait.PollImmediateUntil(olmRetryInterval, func() (bool, error) {
+		subscription, err := client.Resource(subscriptionGVR).Namespace(b.namespace).Get(ctx, b.olm.config.Package, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		currentCSV, _, _ := unstructured.NestedString(subscription.Object, "status", "currentCSV")
+		if currentCSV == "" || currentCSV == previousCSV || (expectedCSV != "" && currentCSV != expectedCSV) {
+			return false, nil
+		}
+		csv = currentCSV
+		if !approve {
+			return true, nil
+		}
+		installPlan, _, _ := unstructured.NestedString(subscription.Object, "status", "installPlanRef", "name")
+		if installPlan == "" {
+			return false, nil
+		}
+		return true, b.approveInstallPlan(ctx, client, installPlan)
+	}, ctx.Done())
+	if err != nil {
+		return "", fmt.Errorf("subscription %s did not resolve to csv %q: %v", b.olm.config.Package, expectedCSV, err)
+	}
+	return csv, nil
+}
+
+// approveInstallPlan approves the given install plan (if not yet approved)
+func (b *BaseOperator) approveInstallPlan(ctx context.Context, client dynamic.Interface, name string) error {
+	installPlan, err := client.Resource(installPlanGVR).Namespace(b.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if approved, _, _ := unstructured.NestedBool(installPlan.Object, "spec", "approved"); approved {
+		return nil
+	}
+	log.Logf("Approving install plan %s", name)
+	if err = unstructured.SetNestedField(installPlan.Object, true, "spec", "approved"); err != nil {
+		return err
+	}
+	_, err = client.Resource(installPlanGVR).Namespace(b.namespace).Update(ctx, installPlan, metav1.UpdateOptions{})
+	return err
+}
+
+// waitForCSV waits for the given CSV to reach the Succeeded phase, updating the
+// operator deployment with the first deployment defined by the CSV
+func (b *BaseOperator) waitForCSV(ctx context.Context, client dynamic.Interface, name string) error {
+	log.Logf("Waiting for csv %s to succeed", name)
+	ctx, cancel := context.WithTimeout(ctx, OLMTimeout)
+	defer cancel()
+
+	var phase string
+	err := wait.PollImmediateUntil(olmRetryInterval, func() (bool, error) {
+		csv, err := client.Resource(csvGVR).Namespace(b.namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		phase, _, _ = unstructured.NestedString(csv.Object, "status", "phase")
+		if phase != "Succeeded" {
+			return false, nil
+		}
+		b.recordOwnedCRDs(csv)
+		deployments, _, _ := unstructured.NestedSlice(csv.Object, "spec", "install", "spec", "deployments")
+		if len(deployments) > 0 {
+			if deployment, ok := deployments[0].(map[string]interface{}); ok {
+				b.deploymentConfig = appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprint(deployment["name"]),
+					Namespace: b.namespace,
+				}}
+			}
+		}
+		return true, nil
+	}, ctx.Done())
+	if err != nil {
+		return fmt.Errorf("csv %s has not succeeded (phase: %q): %v", name, phase, err)
+	}
+	b.olm.installedCSV = name
+	log.Logf("csv %s succeeded", name)
+	return nil
+}
+
+// recordOwnedCRDs records the CRDs owned by the given CSV, so that they
+// can be removed on TeardownSuiteOLM
+func (b *BaseOperator) recordOwnedCRDs(csv *unstructured.Unstructured) {
+	owned, _, _ := unstructured.NestedSlice(csv.Object, "spec", "customresourcedefinitions", "owned")
+	for _, crd := range owned {
+		crdMap, ok := crd.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := fmt.Sprint(crdMap["name"])
+		recorded := false
+		for _, ownedCRD := range b.olm.ownedCRDs {
+			recorded = recorded || ownedCRD == name
+		}
+		if !recorded {
+			b.olm.ownedCRDs = append(b.olm.ownedCRDs, name)
+		}
+	}
+}
+
+func (b *BaseOperator) TeardownEachOLM() error {
+	return b.TeardownEachOLMWithContext(context.TODO())
+}
+
+// TeardownEachOLMWithContext removes the subscription and the installed CSV, along with
+// the OperatorGroup and CatalogSource in case they were created by SetupOLM.
+// Global installations are kept till TeardownSuiteOLM.
+func (b *BaseOperator) TeardownEachOLMWithContext(ctx context.Context) error {
+	if b.globalNamespace {
+		return nil
+	}
+	return b.uninstallOLM(ctx)
+}
+
+func (b *BaseOperator) TeardownSuiteOLM() error {
+	return b.TeardownSuiteOLMWithContext(context.TODO())
+}
+
+// TeardownSuiteOLMWithContext uninstalls the operator and removes the CRDs owned
+// by the CSVs installed (unless CRDs must be kept)
+func (b *BaseOperator) TeardownSuiteOLMWithContext(ctx context.Context) error {
+	client, err := b.dynamicClient()
+	if err != nil {
+		return err
+	}
+	if err := b.uninstallOLM(ctx); err != nil {
+		return err
+	}
+
+	if b.keepCRD {
+		return nil
+	}
+	for _, crd := range b.olm.ownedCRDs {
+		log.Logf("Deleting crd %s", crd)
+		if err := client.Resource(crdGVR).Delete(ctx, crd, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete crd %s: %v", crd, err)
+		}
+	}
+	b.olm.ownedCRDs = nil
+	return nil
+}
+
+func (b *BaseOperator) uninstallOLM(ctx context.Context) error {
+	client, err := b.dynamicClient()
+	if err != nil {
+		return err
+	}
+	config := b.olm.config
+
+	deletions := []struct {
+		gvr       schema.GroupVersionResource
+		namespace string
+		name      string
+		enabled   bool
+	}{
+		{subscriptionGVR, b.namespace, config.Package, true},
+		{csvGVR, b.namespace, b.olm.installedCSV, b.olm.installedCSV != ""},
+		{operatorGroupGVR, b.namespace, b.operatorName, b.olm.operatorGroupCreated},
+		{catalogSourceGVR, b.catalogNamespace(ctx), config.CatalogSource, b.olm.catalogCreated},
+	}
+	for _, d := range deletions {
+		if !d.enabled {
+			continue
+		}
+		err := client.Resource(d.gvr).Namespace(d.namespace).Delete(ctx, d.name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s %s: %v", d.gvr.Resource, d.name, err)
+		}
+	}
+	b.olm.installedCSV = ""
+	b.olm.operatorGroupCreated = false
+	b.olm.catalogCreated = false
+	log.Logf("%s removed from %s", config.Package, b.namespace)
+	return nil
+}
+
+func newUnstructured(gvr schema.GroupVersionResource, kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": spec,
+	}}
+	obj.SetAPIVersion(gvr.GroupVersion().String())
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
@@ -0,0 +1,200 @@
+package operators
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeOLM returns an operator installed through OLM on the given fake dynamic client,
+// where subscriptions resolve to the given CSV and install plan as soon as they are created
+func newFakeOLM(config OLMConfig, csv, installPlan string, objects ...runtime.Object) (*BaseOperator, *dynamicfake.FakeDynamicClient) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		operatorGroupGVR: "OperatorGroupList",
+	}, objects...)
+	client.PrependReactor("create", "subscriptions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		subscription := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		_ = unstructured.SetNestedField(subscription.Object, csv, "status", "currentCSV")
+		_ = unstructured.SetNestedField(subscription.Object, installPlan, "status", "installPlanRef", "name")
+		return false, nil, nil
+	})
+	b := &BaseOperator{
+		namespace:    "olm-test",
+		operatorName: "test-operator",
+		olm:          newOLMOperator(&config),
+		dynClient:    client,
+	}
+	return b, client
+}
+
+func newFakeCSV(name, phase string, ownedCRDs ...string) *unstructured.Unstructured {
+	var owned []interface{}
+	for _, crd := range ownedCRDs {
+		owned = append(owned, map[string]interface{}{"name": crd})
+	}
+	csv := newUnstructured(csvGVR, "ClusterServiceVersion", "olm-test", name, map[string]interface{}{
+		"install": map[string]interface{}{"spec": map[string]interface{}{
+			"deployments": []interface{}{map[string]interface{}{"name": "test-operator-controller"}},
+		}},
+		"customresourcedefinitions": map[string]interface{}{"owned": owned},
+	})
+	_ = unstructured.SetNestedField(csv.Object, phase, "status", "phase")
+	return csv
+}
+
+func exists(t *testing.T, client *dynamicfake.FakeDynamicClient, gvr schema.GroupVersionResource, namespace, name string) bool {
+	_, err := client.Resource(gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		t.Fatalf("unable to get %s %s: %v", gvr.Resource, name, err)
+	}
+	return err == nil
+}
+
+// TestSetupOLM validates the subscription to CSV flow, along with the
+// removal of all resources created by SetupOLM and of the owned CRDs
+func TestSetupOLM(t *testing.T) {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("tests.example.com")
+	b, client := newFakeOLM(OLMConfig{
+		Package:          "test-operator",
+		Channel:          "stable",
+		CatalogSource:    "test-catalog",
+		CatalogNamespace: "olm",
+		CatalogImage:     "quay.io/example/catalog:latest",
+	}, "test-operator.v1.0.0", "", newFakeCSV("test-operator.v1.0.0", "Succeeded", crd.GetName()), crd)
+
+	if err := b.SetupOLMWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error setting up: %v", err)
+	}
+	if b.InstalledCSV() != "test-operator.v1.0.0" || b.deploymentConfig.Name != "test-operator-controller" {
+		t.Errorf("unexpected csv %q and deployment %q", b.InstalledCSV(), b.deploymentConfig.Name)
+	}
+	subscription, err := client.Resource(subscriptionGVR).Namespace("olm-test").Get(context.Background(), "test-operator", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("subscription not created: %v", err)
+	}
+	if spec := subscription.Object["spec"].(map[string]interface{}); spec["channel"] != "stable" || spec["sourceNamespace"] != "olm" || spec["installPlanApproval"] != "Automatic" {
+		t.Errorf("unexpected subscription spec: %v", spec)
+	}
+	if !exists(t, client, catalogSourceGVR, "olm", "test-catalog") || !exists(t, client, operatorGroupGVR, "olm-test", "test-operator") {
+		t.Errorf("catalog source and operator group not created")
+	}
+
+	if err := b.TeardownSuiteOLM(); err != nil {
+		t.Fatalf("unexpected error tearing down: %v", err)
+	}
+	for _, r := range []struct {
+		gvr             schema.GroupVersionResource
+		namespace, name string
+	}{
+		{subscriptionGVR, "olm-test", "test-operator"},
+		{csvGVR, "olm-test", "test-operator.v1.0.0"},
+		{operatorGroupGVR, "olm-test", "test-operator"},
+		{catalogSourceGVR, "olm", "test-catalog"},
+		{crdGVR, "", "tests.example.com"},
+	} {
+		if exists(t, client, r.gvr, r.namespace, r.name) {
+			t.Errorf("%s %s not removed", r.gvr.Resource, r.name)
+		}
+	}
+}
+
+// TestSetupOLMManualApproval validates that the install plan is approved when approval
+// is manual, and that an existing operator group is kept on teardown
+func TestSetupOLMManualApproval(t *testing.T) {
+	installPlan := newUnstructured(installPlanGVR, "InstallPlan", "olm-test", "install-abc12", map[string]interface{}{
+		"approved": false,
+	})
+	group := newUnstructured(operatorGroupGVR, "OperatorGroup", "olm-test", "existing", map[string]interface{}{})
+	b, client := newFakeOLM(OLMConfig{
+		Package:             "test-operator",
+		StartingCSV:         "test-operator.v1.0.0",
+		CatalogSource:       "test-catalog",
+		CatalogNamespace:    "olm",
+		InstallPlanApproval: InstallPlanApprovalManual,
+	}, "test-operator.v1.0.0", "install-abc12", newFakeCSV("test-operator.v1.0.0", "Succeeded"), installPlan, group)
+
+	if err := b.SetupOLMWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error setting up: %v", err)
+	}
+	approved, err := client.Resource(installPlanGVR).Namespace("olm-test").Get(context.Background(), "install-abc12", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get install plan: %v", err)
+	}
+	if value, _, _ := unstructured.NestedBool(approved.Object, "spec", "approved"); !value {
+		t.Errorf("install plan not approved")
+	}
+	if exists(t, client, operatorGroupGVR, "olm-test", "test-operator") {
+		t.Errorf("operator group created in a namespace that already had one")
+	}
+
+	if err := b.TeardownEachOLM(); err != nil {
+		t.Fatalf("unexpected error tearing down: %v", err)
+	}
+	if exists(t, client, subscriptionGVR, "olm-test", "test-operator") || exists(t, client, csvGVR, "olm-test", "test-operator.v1.0.0") {
+		t.Errorf("subscription and csv not removed")
+	}
+	if !exists(t, client, operatorGroupGVR, "olm-test", "existing") {
+		t.Errorf("existing operator group removed")
+	}
+}
+
+// TestSetupOLMCancelled validates that waiting for the CSV stops when the context is done
+func TestSetupOLMCancelled(t *testing.T) {
+	b, _ := newFakeOLM(OLMConfig{
+		Package:          "test-operator",
+		CatalogSource:    "test-catalog",
+		CatalogNamespace: "olm",
+	}, "test-operator.v1.0.0", "", newFakeCSV("test-operator.v1.0.0", "Installing"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := b.SetupOLMWithContext(ctx)
+	if err == nil || !strings.Contains(err.Error(), `phase: "Installing"`) {
+		t.Errorf("expected csv not to succeed, got: %v", err)
+	}
+}
+
+// TestTeardownEachThenSuiteOLM validates that the CRDs owned by the CSV are still
+// removed on TeardownSuiteOLM, once the CSV has been removed by TeardownEachOLM
+func TestTeardownEachThenSuiteOLM(t *testing.T) {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("tests.example.com")
+	b, client := newFakeOLM(OLMConfig{
+		Package:          "test-operator",
+		CatalogSource:    "test-catalog",
+		CatalogNamespace: "olm",
+	}, "test-operator.v1.0.0", "", newFakeCSV("test-operator.v1.0.0", "Succeeded", crd.GetName()), crd)
+
+	ctx := context.Background()
+	if err := b.SetupOLMWithContext(ctx); err != nil {
+		t.Fatalf("unexpected error setting up: %v", err)
+	}
+	if err := b.TeardownEachOLMWithContext(ctx); err != nil {
+		t.Fatalf("unexpected error tearing down: %v", err)
+	}
+	if exists(t, client, csvGVR, "olm-test", "test-operator.v1.0.0") {
+		t.Errorf("csv not removed")
+	}
+	if !exists(t, client, crdGVR, "", "tests.example.com") {
+		t.Errorf("crd removed before the suite completes")
+	}
+	if err := b.TeardownSuiteOLMWithContext(ctx); err != nil {
+		t.Fatalf("unexpected error tearing down suite: %v", err)
+	}
+	if exists(t, client, crdGVR, "", "tests.example.com") {
+		t.Errorf("crd not removed")
+	}
+}
@@ -20,10 +20,19 @@ type OperatorSetupBuilder interface {
 	WithApiVersion(apiVersion string) OperatorSetupBuilder
 	WithYamls(yamls [][]byte) OperatorSetupBuilder
 	WithGlobalNamespace() OperatorSetupBuilder
+	WithOLM(config OLMConfig) OperatorSetupBuilder
+	WithResourceTracker(tracker ResourceTracker) OperatorSetupBuilder
 	Build() (OperatorSetup, error)
 	OperatorType() OperatorType
 }
 
+// OperatorBuilderInfo is implemented by the builders able to tell
+// the name and image of the operator before it is built
+type OperatorBuilderInfo interface {
+	OperatorName() string
+	OperatorImage() string
+}
+
 type OperatorSetup interface {
 	Interface() interface{}
 	Namespace() string
@@ -37,6 +46,7 @@ type OperatorSetup interface {
 	DeleteDeployment() error
 	CreateDeployment() error
 	GetDeployment() (*appsv1.Deployment, error)
+	Upgrade(upgrade OperatorUpgrade) error
 	TeardownEach() error
 	TeardownSuite() error
 }
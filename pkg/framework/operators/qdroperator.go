@@ -0,0 +1,113 @@
+package operators
+
+import (
+	"fmt"
+
+	"github.com/rh-messaging/shipshape/pkg/api/interconnect/v1alpha1"
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// QdrOperatorBuilder builds the Qpid Dispatch (Interconnect) operator
+type QdrOperatorBuilder struct {
+	BaseOperatorBuilder
+}
+
+func (b *QdrOperatorBuilder) Build() (OperatorSetup, error) {
+	qdr := &QdrOperator{}
+	if err := qdr.InitFromBaseOperatorBuilder(&b.BaseOperatorBuilder); err != nil {
+		return qdr, err
+	}
+
+	if qdrClient, err := v1alpha1.NewForConfig(b.restConfig); err != nil {
+		return qdr, err
+	} else {
+		qdr.qdrClient = qdrClient
+	}
+
+	qdr.customCommand = b.customCommand
+	// Setting up the defaults
+	if qdr.IsOLM() || qdr.yamls != nil {
+
+	} else if qdr.yamlURLs == nil {
+		baseImportPath := "https://raw.githubusercontent.com/interconnectedcloud/qdr-operator/master/deploy/"
+		qdr.yamlURLs = []string{
+			baseImportPath + "service_account.yaml",
+			baseImportPath + "role.yaml",
+			baseImportPath + "role_binding.yaml",
+			baseImportPath + "crds/interconnectedcloud_v1alpha1_interconnect_crd.yaml",
+			baseImportPath + "operator.yaml",
+		}
+	}
+
+	return qdr, nil
+}
+
+func (b *QdrOperatorBuilder) OperatorType() OperatorType {
+	return OperatorTypeQdr
+}
+
+type QdrOperator struct {
+	BaseOperator
+	qdrClient v1alpha1.Interface
+}
+
+func (q *QdrOperator) Setup() error {
+	if q.IsOLM() {
+		return q.SetupOLM()
+	}
+	log.Logf("Setting up from YAMLs (qdroperator)")
+	return q.SetupYamls()
+}
+
+// TeardownEach removes the namespaced operator resources, even if CRDs are kept
+func (q *QdrOperator) TeardownEach() error {
+	if q.IsOLM() {
+		return q.TeardownEachOLM()
+	}
+	if err := q.deleteNamespacedResources(); err != nil {
+		return fmt.Errorf("failed to remove %s resources: %v", q.Name(), err)
+	}
+	return nil
+}
+
+func (q *QdrOperator) Image() string {
+	return q.image
+}
+
+func (q *QdrOperator) CRDNames() []string {
+	return []string{v1alpha1.Resource + "." + q.GroupName()}
+}
+
+func (q *QdrOperator) GroupName() string {
+	return v1alpha1.GroupName
+}
+
+func (q *QdrOperator) APIVersion() string {
+	return q.apiVersion
+}
+
+func (q *QdrOperator) Name() string {
+	return q.operatorName
+}
+
+// Interface returns a v1alpha1.Interface to manage Interconnect resources
+func (q *QdrOperator) Interface() interface{} {
+	return q.qdrClient
+}
+
+func (q *QdrOperator) UpdateDeployment(deployment *appsv1.Deployment) error {
+	return q.BaseOperator.UpdateDeployment(deployment)
+}
+
+func (q *QdrOperator) DeleteDeployment() error {
+	return q.BaseOperator.DeleteDeployment()
+}
+
+func (q *QdrOperator) CreateDeployment() error {
+	return q.BaseOperator.CreateDeployment()
+}
+
+func (q *QdrOperator) GetDeployment() (*appsv1.Deployment, error) {
+	return q.BaseOperator.GetDeployment()
+}
@@ -0,0 +1,87 @@
+package operators
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+)
+
+var (
+	// SkupperTimeout is the maximum amount of time a skupper command can run
+	// when the provided context has no deadline
+	SkupperTimeout = 2 * time.Minute
+)
+
+// SkupperResult holds the outcome of a skupper command
+type SkupperResult struct {
+	Args     []string
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+}
+
+// CommandLine returns the command line that has been executed
+func (r *SkupperResult) CommandLine() string {
+	return strings.Join(r.Args, " ")
+}
+
+func (s *SkupperOperator) Run(args ...string) (*SkupperResult, error) {
+	return s.RunWithContext(context.TODO(), args...)
+}
+
+// RunWithContext runs the skupper CLI with the given arguments, waiting for it to complete.
+// The returned result holds the captured output, even when the command fails. A non-zero
+// exit code, as well as the command not completing in time, is returned as an error.
+func (s *SkupperOperator) RunWithContext(ctx context.Context, args ...string) (*SkupperResult, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, SkupperTimeout)
+		defer cancel()
+	}
+
+	result := &SkupperResult{Args: append([]string{s.SkupperBin()}, args...)}
+	log.Logf("[%s] Running: %s", s.contextName(args), result.CommandLine())
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.SkupperBin(), args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err := cmd.Run()
+	result.Duration = time.Since(start)
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.ExitCode = cmd.ProcessState.ExitCode()
+
+	if ctx.Err() != nil {
+		return result, fmt.Errorf("%s did not complete: %v", result.CommandLine(), ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return result, fmt.Errorf("%s failed with exit code %d: %s", result.CommandLine(), result.ExitCode, strings.TrimSpace(result.Stderr+result.Stdout))
+	}
+	if err != nil {
+		return result, fmt.Errorf("unable to run %s: %v", result.CommandLine(), err)
+	}
+	return result, nil
+}
+
+// contextName returns the kubeconfig context the command runs against
+func (s *SkupperOperator) contextName(args []string) string {
+	for i, arg := range args {
+		if arg == "--context" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	if s.rawConfig != nil {
+		return s.rawConfig.CurrentContext
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package operators
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestSkupperRun(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+  fail) echo "error: $2" >&2; exit 3 ;;
+  sleep) exec sleep 5 ;;
+  *) echo "$@" ;;
+esac
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "skupper"), []byte(script), 0755); err != nil {
+		t.Fatalf("unable to write fake skupper: %v", err)
+	}
+	s := &SkupperOperator{SkupperPath: dir + "/"}
+
+	result, err := s.Run("status", "--context", "ctx1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.TrimSpace(result.Stdout) != "status --context ctx1" || result.ExitCode != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	result, err = s.Run("fail", "boom")
+	if err == nil || !strings.Contains(err.Error(), "exit code 3") || !strings.Contains(err.Error(), "error: boom") {
+		t.Errorf("expected exit code error, got: %v", err)
+	}
+	if result.ExitCode != 3 || strings.TrimSpace(result.Stderr) != "error: boom" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := s.RunWithContext(ctx, "sleep"); err == nil || !strings.Contains(err.Error(), "did not complete") {
+		t.Errorf("expected timeout error, got: %v", err)
+	}
+}
+
+func TestSkupperInitWithSiteConfig(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+echo "$@" > "$(dirname "$0")/args"
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "skupper"), []byte(script), 0755); err != nil {
+		t.Fatalf("unable to write fake skupper: %v", err)
+	}
+	builder := &SkupperOperatorBuilder{}
+	builder.WithSkupperPath(dir + "/")
+	builder.WithSiteConfig(SkupperSiteConfig{Name: "east", RouterMode: "edge"})
+	s := &builder.skupper
+	s.namespace = "ns1"
+	s.rawConfig = &clientcmdapi.Config{CurrentContext: "ctx1"}
+
+	if err := s.Setup(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	args, err := ioutil.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatalf("unable to read arguments: %v", err)
+	}
+	if expected := "init --site-name east --router-mode edge --namespace ns1 --context ctx1"; strings.TrimSpace(string(args)) != expected {
+		t.Errorf("got: %s, expected: %s", strings.TrimSpace(string(args)), expected)
+	}
+}
@@ -1,9 +1,8 @@
 package operators
 
 import (
-	"context"
+	"fmt"
 	"os"
-	"os/exec"
 
 	appsv1 "k8s.io/api/apps/v1"
 )
@@ -33,9 +32,17 @@ func (s *SkupperOperatorBuilder) OperatorType() OperatorType {
 	return OperatorTypeSkupper
 }
 
-//
-// Future use
-//
+// WithSiteController makes the operator install the site controller and initialize
+// the site through the skupper-site ConfigMap, instead of using the skupper CLI
+func (b *SkupperOperatorBuilder) WithSiteController(config SkupperSiteConfig) {
+	b.skupper.site = &config
+}
+
+// WithSiteConfig sets the site settings passed to "skupper init", when the
+// site is initialized through the skupper CLI
+func (b *SkupperOperatorBuilder) WithSiteConfig(config SkupperSiteConfig) {
+	b.skupper.initConfig = config
+}
 
 func (b *SkupperOperatorBuilder) WithQdrouterdImage(image string) {
 	b.skupper.QdrouterdImage = image
@@ -49,10 +56,6 @@ func (b *SkupperOperatorBuilder) WithProxyImage(image string) {
 	b.skupper.ProxyImage = image
 }
 
-//
-// END OF - Future use
-//
-
 func (b *SkupperOperatorBuilder) Build() (OperatorSetup, error) {
 	if err := b.skupper.InitFromBaseOperatorBuilder(&b.BaseOperatorBuilder); err != nil {
 		return &b.skupper, err
@@ -63,6 +66,14 @@ func (b *SkupperOperatorBuilder) Build() (OperatorSetup, error) {
 	if os.Getenv("OPERATOR_TESTING") != "" {
 		b.ClusterLocal(true)
 	}
+	if b.skupper.IsSiteController() {
+		if b.skupper.yamls == nil {
+			b.skupper.yamls = skupperSiteControllerYamls()
+		}
+		b.skupper.extraEnv = b.skupper.imageEnv()
+		// The deployment named after the operator is created by the site controller
+		b.skupper.keepDeploymentName = true
+	}
 	return &b.skupper, nil
 }
 
@@ -74,6 +85,8 @@ type SkupperOperator struct {
 	ControllerImage string
 	ProxyImage      string
 	SkupperPath     string
+	site            *SkupperSiteConfig
+	initConfig      SkupperSiteConfig
 }
 
 func (s *SkupperOperator) SkupperBin() string {
@@ -84,10 +97,17 @@ func (s *SkupperOperator) Name() string {
 	return s.operatorName
 }
 
+// IsSiteController returns true if the site is managed by a site controller
+// instead of the skupper CLI
+func (s *SkupperOperator) IsSiteController() bool {
+	return s.site != nil
+}
+
 func (s *SkupperOperator) Setup() error {
+	if s.IsSiteController() {
+		return s.setupSite()
+	}
 	// run skupper init with provided flags from builder
-	cmdCtx := context.TODO()
-
 	// Building args list
 	args := []string{
 		"init",
@@ -95,25 +115,34 @@ func (s *SkupperOperator) Setup() error {
 	if s.ClusterLocal {
 		args = append(args, "--cluster-local")
 	}
+	args = append(args, s.initConfig.initArgs()...)
 
 	// Using provided context
 	args = append(args, "--namespace", s.namespace)
 	args = append(args, "--context", s.rawConfig.CurrentContext)
 
-	cmd := exec.CommandContext(cmdCtx, s.SkupperBin(), args...)
-	err := cmd.Start()
-
+	_, err := s.Run(args...)
 	return err
 }
 
+// Upgrade is not supported, as skupper sites are initialized (and upgraded)
+// through the skupper CLI or the site controller instead of an operator
+func (s *SkupperOperator) Upgrade(upgrade OperatorUpgrade) error {
+	return fmt.Errorf("upgrading %s is not supported", s.Name())
+}
+
 func (s *SkupperOperator) TeardownEach() error {
+	if s.IsSiteController() {
+		return s.teardownSite()
+	}
 	return nil
 }
 
 func (s *SkupperOperator) TeardownSuite() error {
+	if s.IsSiteController() {
+		return nil
+	}
 	// run skupper delete
-	cmdCtx := context.TODO()
-
 	// Building args list
 	args := []string{
 		"delete",
@@ -123,9 +152,7 @@ func (s *SkupperOperator) TeardownSuite() error {
 	args = append(args, "--namespace", s.namespace)
 	args = append(args, "--context", s.rawConfig.CurrentContext)
 
-	cmd := exec.CommandContext(cmdCtx, s.SkupperBin(), args...)
-	err := cmd.Start()
-
+	_, err := s.Run(args...)
 	return err
 }
 
@@ -0,0 +1,219 @@
+package operators
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SkupperSiteConfigMap is the ConfigMap watched by the site controller to initialize a site
+	SkupperSiteConfigMap = "skupper-site"
+	// SkupperSiteControllerImage is the default site controller image
+	SkupperSiteControllerImage = "quay.io/skupper/site-controller:master"
+	skupperSiteControllerName  = "skupper-site-controller"
+)
+
+// SkupperSiteConfig describes a site initialized declaratively, through the skupper-site
+// ConfigMap, by a site controller installed in the namespace (no skupper CLI needed)
+type SkupperSiteConfig struct {
+	// Name of the site (defaults to the namespace)
+	Name string `json:"name,omitempty"`
+	// RouterMode is either interior (default) or edge
+	RouterMode string `json:"routerMode,omitempty"`
+	// Ingress type, like route, loadbalancer, nodeport or none
+	Ingress               string `json:"ingress,omitempty"`
+	Console               bool   `json:"console,omitempty"`
+	ConsoleAuthentication string `json:"consoleAuthentication,omitempty"`
+	ConsoleUser           string `json:"consoleUser,omitempty"`
+	ConsolePassword       string `json:"consolePassword,omitempty"`
+	RouterCPU             string `json:"routerCPU,omitempty"`
+	RouterMemory          string `json:"routerMemory,omitempty"`
+	RouterLogging         string `json:"routerLogging,omitempty"`
+	RouterDebugMode       string `json:"routerDebugMode,omitempty"`
+	// Options holds any other skupper-site setting, by key
+	// (only supported by the site controller)
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// data returns the skupper-site ConfigMap data for the given namespace
+func (c *SkupperSiteConfig) data(namespace string, clusterLocal bool) map[string]string {
+	data := map[string]string{
+		"name":    c.Name,
+		"console": strconv.FormatBool(c.Console),
+	}
+	if data["name"] == "" {
+		data["name"] = namespace
+	}
+	if clusterLocal {
+		data["cluster-local"] = "true"
+	}
+	for key, value := range map[string]string{
+		"router-mode":            c.RouterMode,
+		"ingress":                c.Ingress,
+		"console-authentication": c.ConsoleAuthentication,
+		"console-user":           c.ConsoleUser,
+		"console-password":       c.ConsolePassword,
+		"router-cpu":             c.RouterCPU,
+		"router-memory":          c.RouterMemory,
+		"router-logging":         c.RouterLogging,
+		"router-debug-mode":      c.RouterDebugMode,
+	} {
+		if value != "" {
+			data[key] = value
+		}
+	}
+	for key, value := range c.Options {
+		data[key] = value
+	}
+	return data
+}
+
+// initArgs returns the "skupper init" flags for the site settings, used
+// when the site is initialized through the skupper CLI
+func (c *SkupperSiteConfig) initArgs() []string {
+	var args []string
+	for _, flag := range []struct{ name, value string }{
+		{"--site-name", c.Name},
+		{"--router-mode", c.RouterMode},
+		{"--ingress", c.Ingress},
+		{"--console-auth", c.ConsoleAuthentication},
+		{"--console-user", c.ConsoleUser},
+		{"--console-password", c.ConsolePassword},
+		{"--router-cpu", c.RouterCPU},
+		{"--router-memory", c.RouterMemory},
+		{"--router-logging", c.RouterLogging},
+		{"--router-debug-mode", c.RouterDebugMode},
+	} {
+		if flag.value != "" {
+			args = append(args, flag.name, flag.value)
+		}
+	}
+	if c.Console {
+		args = append(args, "--enable-console")
+	}
+	return args
+}
+
+// imageEnv returns the environment variables used by the site controller to
+// override the images of the site components
+func (s *SkupperOperator) imageEnv() []corev1.EnvVar {
+	var env []corev1.EnvVar
+	add := func(image string, names ...string) {
+		if image == "" {
+			return
+		}
+		for _, name := range names {
+			env = append(env, corev1.EnvVar{Name: name, Value: image})
+		}
+	}
+	add(s.QdrouterdImage, "QDROUTERD_IMAGE", "SKUPPER_ROUTER_IMAGE")
+	add(s.ControllerImage, "SKUPPER_CONTROLLER_IMAGE", "SKUPPER_SERVICE_CONTROLLER_IMAGE")
+	add(s.ProxyImage, "SKUPPER_PROXY_IMAGE")
+	return env
+}
+
+// setupSite installs the site controller and creates the skupper-site ConfigMap
+func (s *SkupperOperator) setupSite() error {
+	log.Logf("Setting up skupper site controller (ns: %s)", s.namespace)
+	if err := s.SetupYamls(); err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: SkupperSiteConfigMap, Namespace: s.namespace},
+		Data:       s.site.data(s.namespace, s.ClusterLocal),
+	}
+	if _, err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Create(context.TODO(), configMap, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create %s config map: %v", SkupperSiteConfigMap, err)
+	}
+	return nil
+}
+
+// teardownSite removes the skupper-site ConfigMap (making the site controller remove
+// the site) and the site controller itself
+func (s *SkupperOperator) teardownSite() error {
+	err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Delete(context.TODO(), SkupperSiteConfigMap, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s config map: %v", SkupperSiteConfigMap, err)
+	}
+	return s.BaseOperator.TeardownEach()
+}
+
+// skupperSiteControllerYamls returns the resources needed to run a site
+// controller watching its own namespace
+func skupperSiteControllerYamls() [][]byte {
+	return [][]byte{
+		[]byte(`apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ` + skupperSiteControllerName + `
+  labels:
+    application: ` + skupperSiteControllerName),
+		[]byte(`apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: ` + skupperSiteControllerName + `
+  labels:
+    application: ` + skupperSiteControllerName + `
+rules:
+- apiGroups: [""]
+  resources: ["configmaps", "pods", "pods/exec", "services", "secrets", "serviceaccounts", "events"]
+  verbs: ["get", "list", "watch", "create", "update", "delete", "patch"]
+- apiGroups: ["apps"]
+  resources: ["deployments", "statefulsets", "daemonsets"]
+  verbs: ["get", "list", "watch", "create", "update", "delete", "patch"]
+- apiGroups: ["route.openshift.io"]
+  resources: ["routes"]
+  verbs: ["get", "list", "watch", "create", "update", "delete"]
+- apiGroups: ["networking.k8s.io"]
+  resources: ["ingresses", "networkpolicies"]
+  verbs: ["get", "list", "watch", "create", "update", "delete"]
+- apiGroups: ["rbac.authorization.k8s.io"]
+  resources: ["rolebindings", "roles"]
+  verbs: ["get", "list", "watch", "create", "update", "delete"]`),
+		[]byte(`apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: ` + skupperSiteControllerName + `
+  labels:
+    application: ` + skupperSiteControllerName + `
+subjects:
+- kind: ServiceAccount
+  name: ` + skupperSiteControllerName + `
+roleRef:
+  kind: Role
+  name: ` + skupperSiteControllerName + `
+  apiGroup: rbac.authorization.k8s.io`),
+		[]byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ` + skupperSiteControllerName + `
+  labels:
+    application: ` + skupperSiteControllerName + `
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      application: ` + skupperSiteControllerName + `
+  template:
+    metadata:
+      labels:
+        application: ` + skupperSiteControllerName + `
+    spec:
+      serviceAccountName: ` + skupperSiteControllerName + `
+      containers:
+      - name: site-controller
+        image: ` + SkupperSiteControllerImage + `
+        env:
+        - name: WATCH_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace`),
+	}
+}
@@ -0,0 +1,75 @@
+package operators
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSkupperSiteConfigData(t *testing.T) {
+	config := SkupperSiteConfig{
+		RouterMode:            "edge",
+		Console:               true,
+		ConsoleAuthentication: "internal",
+		ConsoleUser:           "admin",
+		ConsolePassword:       "secret",
+		Options:               map[string]string{"service-sync": "false"},
+	}
+	expected := map[string]string{
+		"name":                   "ns1",
+		"router-mode":            "edge",
+		"cluster-local":          "true",
+		"console":                "true",
+		"console-authentication": "internal",
+		"console-user":           "admin",
+		"console-password":       "secret",
+		"service-sync":           "false",
+	}
+	if data := config.data("ns1", true); !reflect.DeepEqual(data, expected) {
+		t.Errorf("unexpected data: %v", data)
+	}
+
+	config = SkupperSiteConfig{Name: "site1"}
+	expected = map[string]string{"name": "site1", "console": "false"}
+	if data := config.data("ns1", false); !reflect.DeepEqual(data, expected) {
+		t.Errorf("unexpected data: %v", data)
+	}
+}
+
+func TestSkupperSiteConfigInitArgs(t *testing.T) {
+	config := SkupperSiteConfig{
+		Name:                  "east",
+		RouterMode:            "edge",
+		Ingress:               "none",
+		Console:               true,
+		ConsoleAuthentication: "internal",
+		RouterLogging:         "trace",
+	}
+	expected := []string{
+		"--site-name", "east",
+		"--router-mode", "edge",
+		"--ingress", "none",
+		"--console-auth", "internal",
+		"--router-logging", "trace",
+		"--enable-console",
+	}
+	if args := config.initArgs(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("unexpected args: %v", args)
+	}
+	if args := (&SkupperSiteConfig{}).initArgs(); len(args) != 0 {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestSkupperImageEnv(t *testing.T) {
+	s := &SkupperOperator{QdrouterdImage: "router:1", ProxyImage: "proxy:1"}
+	expected := []corev1.EnvVar{
+		{Name: "QDROUTERD_IMAGE", Value: "router:1"},
+		{Name: "SKUPPER_ROUTER_IMAGE", Value: "router:1"},
+		{Name: "SKUPPER_PROXY_IMAGE", Value: "proxy:1"},
+	}
+	if env := s.imageEnv(); !reflect.DeepEqual(env, expected) {
+		t.Errorf("unexpected env: %v", env)
+	}
+}
@@ -1,5 +1,7 @@
 package operators
 
+import "fmt"
+
 type OperatorType int
 
 const (
@@ -8,14 +10,59 @@ const (
 	OperatorTypeSkupper
 )
 
+func (o OperatorType) String() string {
+	switch o {
+	case OperatorTypeQdr:
+		return "qdr"
+	case OperatorTypeBroker:
+		return "broker"
+	case OperatorTypeSkupper:
+		return "skupper"
+	default:
+		return fmt.Sprintf("OperatorType(%d)", int(o))
+	}
+}
+
+// ParseOperatorType returns the OperatorType with the given name
+func ParseOperatorType(name string) (OperatorType, error) {
+	for _, operators := range []map[OperatorType]OperatorSetupBuilder{SupportedOperators, OptionalOperators} {
+		for operatorType := range operators {
+			if operatorType.String() == name {
+				return operatorType, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unsupported operator type: %s", name)
+}
+
+// NewOperatorBuilder returns a new builder for the given operator type, holding
+// the same defaults as the one from SupportedOperators (or OptionalOperators).
+// Unlike the builders from those maps, it can be customized without affecting
+// other contexts.
+func NewOperatorBuilder(operatorType OperatorType) (OperatorSetupBuilder, error) {
+	defaults, ok := SupportedOperators[operatorType]
+	if !ok {
+		defaults = OptionalOperators[operatorType]
+	}
+	switch builder := defaults.(type) {
+	case *QdrOperatorBuilder:
+		newBuilder := *builder
+		return &newBuilder, nil
+	case *BrokerOperatorBuilder:
+		newBuilder := *builder
+		return &newBuilder, nil
+	case *SkupperOperatorBuilder:
+		newBuilder := *builder
+		return &newBuilder, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator type: %v", operatorType)
+	}
+}
+
 var (
+	// SupportedOperators are the operators installed by default, when the
+	// Framework is built without explicit builders
 	SupportedOperators = map[OperatorType]OperatorSetupBuilder{
-		/*		OperatorTypeQdr: &QdrOperatorBuilder{BaseOperatorBuilder{
-				image:        "quay.io/interconnectedcloud/qdr-operator",
-				operatorName: "qdr-operator",
-				keepCdrs:     true,
-				apiVersion:   "v1alpha1",
-			}},*/
 		OperatorTypeBroker: &BrokerOperatorBuilder{BaseOperatorBuilder{
 			image:        "quay.io/artemiscloud/activemq-artemis-operator:latest", //or  registry.redhat.io/amq7/amq-broker-rhel7-operator
 			operatorName: "activemq-artemis-operator",
@@ -26,4 +73,15 @@ var (
 			operatorName: "skupper-router",
 		}},
 	}
+
+	// OptionalOperators are the operators that are only installed when requested,
+	// through builders returned by NewOperatorBuilder (or from a topology file)
+	OptionalOperators = map[OperatorType]OperatorSetupBuilder{
+		OperatorTypeQdr: &QdrOperatorBuilder{BaseOperatorBuilder{
+			image:        "quay.io/interconnectedcloud/qdr-operator",
+			operatorName: "qdr-operator",
+			keepCdrs:     true,
+			apiVersion:   "v1alpha1",
+		}},
+	}
 )
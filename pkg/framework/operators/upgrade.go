@@ -0,0 +1,233 @@
+package operators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	// UpgradeTimeout is the maximum amount of time to wait for
+	// an upgraded operator deployment to be rolled out
+	UpgradeTimeout = 10 * time.Minute
+	// UpgradeRetryInterval is how often the operator deployment is
+	// checked while waiting for it to be rolled out
+	UpgradeRetryInterval = 5 * time.Second
+)
+
+// OperatorUpgrade describes the version an operator must be upgraded to.
+// Operators installed from YAMLs are upgraded by applying the given Yamls
+// or YamlURLs (CRDs, RBAC and deployment) and/or by replacing the Image,
+// while operators installed through OLM are upgraded by switching to the
+// given Channel and/or waiting for the given CSV.
+type OperatorUpgrade struct {
+	Image    string
+	YamlURLs []string
+	Yamls    [][]byte
+	Channel  string
+	CSV      string
+}
+
+// Upgrade upgrades the operator in place and waits for the new operator
+// deployment to be rolled out. Resources managed by the operator are kept.
+func (b *BaseOperator) Upgrade(upgrade OperatorUpgrade) error {
+	if b.IsOLM() {
+		if err := b.upgradeOLM(upgrade); err != nil {
+			return err
+		}
+	} else {
+		if err := b.upgradeYamls(upgrade); err != nil {
+			return err
+		}
+	}
+	return b.WaitForRollout()
+}
+
+// upgradeYamls applies the new operator resources, replacing existing ones
+func (b *BaseOperator) upgradeYamls(upgrade OperatorUpgrade) error {
+	var items [][]byte
+	for _, url := range upgrade.YamlURLs {
+		jsonItem, err := b.loadJson(url)
+		if err != nil {
+			return err
+		}
+		items = append(items, jsonItem)
+	}
+	for _, item := range upgrade.Yamls {
+		jsonItem, err := yaml.YAMLToJSON(item)
+		if err != nil {
+			return err
+		}
+		items = append(items, jsonItem)
+	}
+
+	deploymentUpdated := false
+	for _, jsonItem := range items {
+		var def DefinitionStruct
+		if err := json.Unmarshal(jsonItem, &def); err != nil {
+			return err
+		}
+		switch def.Kind {
+		case "Deployment":
+			if err := b.upgradeDeployment(jsonItem, upgrade.Image); err != nil {
+				return err
+			}
+			deploymentUpdated = true
+		default:
+			log.Logf("Upgrading %s", def.Kind)
+			yamlItem, err := yaml.JSONToYAML(jsonItem)
+			if err != nil {
+				return err
+			}
+			// CRDs are removed on TeardownSuite, other new resources through the tracker
+			tracker := b.tracker
+			if def.Kind == "CustomResourceDefinition" {
+				tracker = nil
+			}
+			if err := b.manageResourcesFromYAMLBytes(dynamicActionForceApply, yamlItem, tracker); err != nil {
+				return err
+			}
+			if def.Kind == "CustomResourceDefinition" {
+				b.crds = append(b.crds, yamlItem)
+			}
+		}
+	}
+
+	// Only the image has been changed
+	if !deploymentUpdated && upgrade.Image != "" {
+		deployment, err := b.GetDeployment()
+		if err != nil {
+			return err
+		}
+		log.Logf("Upgrading %s image to %s", deployment.Name, upgrade.Image)
+		deployment.Spec.Template.Spec.Containers[0].Image = upgrade.Image
+		if err := b.UpdateDeployment(deployment); err != nil {
+			return fmt.Errorf("failed to upgrade %s deployment: %v", deployment.Name, err)
+		}
+		b.image = upgrade.Image
+	}
+	return nil
+}
+
+// upgradeDeployment replaces the operator deployment with the given definition,
+// overriding its image only if one is given (the builder settings are not applied)
+func (b *BaseOperator) upgradeDeployment(jsonItem []byte, image string) error {
+	current, err := b.GetDeployment()
+	if err != nil {
+		return err
+	}
+	deployment := appsv1.Deployment{}
+	if err := json.Unmarshal(jsonItem, &deployment); err != nil {
+		return fmt.Errorf("failed to load deployment from json definition: %v", err)
+	}
+	if len(deployment.Spec.Template.Spec.Containers) == 0 {
+		return fmt.Errorf("deployment %s has no containers", deployment.Name)
+	}
+	if image != "" {
+		deployment.Spec.Template.Spec.Containers[0].Image = image
+	}
+	// Replacing the current deployment in place
+	deployment.Name = current.Name
+	deployment.Namespace = b.namespace
+	deployment.ResourceVersion = current.ResourceVersion
+	b.deploymentConfig = deployment
+
+	log.Logf("Upgrading deployment %s", b.deploymentConfig.Name)
+	if err := b.UpdateDeployment(&b.deploymentConfig); err != nil {
+		return fmt.Errorf("failed to upgrade %s deployment: %v", b.deploymentConfig.Name, err)
+	}
+	b.image = deployment.Spec.Template.Spec.Containers[0].Image
+	return nil
+}
+
+// upgradeOLM switches the subscription channel (if requested) and waits for
+// the new CSV to succeed, approving its install plan if approval is manual
+func (b *BaseOperator) upgradeOLM(upgrade OperatorUpgrade) error {
+	client, err := b.dynamicClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	previousCSV := b.olm.installedCSV
+
+	if upgrade.Channel != "" && upgrade.Channel != b.olm.config.Channel {
+		log.Logf("Switching %s subscription to channel %s", b.olm.config.Package, upgrade.Channel)
+		subscription, err := client.Resource(subscriptionGVR).Namespace(b.namespace).Get(ctx, b.olm.config.Package, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if err = unstructured.SetNestedField(subscription.Object, upgrade.Channel, "spec", "channel"); err != nil {
+			return err
+		}
+		if _, err = client.Resource(subscriptionGVR).Namespace(b.namespace).Update(ctx, subscription, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update subscription %s: %v", b.olm.config.Package, err)
+		}
+		b.olm.config.Channel = upgrade.Channel
+	}
+
+	approve := b.olm.config.InstallPlanApproval == InstallPlanApprovalManual
+	csv, err := b.waitForSubscriptionCSV(ctx, client, upgrade.CSV, previousCSV, approve)
+	if err != nil {
+		return err
+	}
+	return b.waitForCSV(ctx, client, csv)
+}
+
+// WaitForRollout waits for all replicas of the operator deployment to be updated and available.
+// Transient errors getting the deployment (like it being replaced) are retried till UpgradeTimeout.
+func (b *BaseOperator) WaitForRollout() error {
+	var deployment *appsv1.Deployment
+	var lastErr error
+	err := wait.PollImmediate(UpgradeRetryInterval, UpgradeTimeout, func() (bool, error) {
+		var err error
+		if deployment, err = b.GetDeployment(); err != nil {
+			if isTransient(err) {
+				log.Logf("Unable to get %s deployment, retrying: %v", b.deploymentConfig.Name, err)
+				lastErr = err
+				return false, nil
+			}
+			return false, err
+		}
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		status := deployment.Status
+		return status.ObservedGeneration >= deployment.Generation &&
+			status.UpdatedReplicas == replicas &&
+			status.AvailableReplicas == replicas &&
+			status.Replicas == replicas, nil
+	})
+	if err == wait.ErrWaitTimeout && lastErr != nil {
+		err = fmt.Errorf("%v (last error: %v)", err, lastErr)
+	}
+	if err != nil {
+		return fmt.Errorf("operator %s has not been rolled out: %v", b.deploymentConfig.Name, err)
+	}
+	log.Logf("Operator %s rolled out", deployment.Name)
+	return nil
+}
+
+// isTransient returns true for errors that may go away by retrying, like the
+// resource being replaced or the API server being temporarily unavailable
+func isTransient(err error) bool {
+	return apierrors.IsNotFound(err) ||
+		apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsInternalError(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		utilnet.IsConnectionReset(err) ||
+		utilnet.IsProbableEOF(err)
+}
@@ -0,0 +1,216 @@
+package operators
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// newFakeOperator returns an operator using fake clients, where server-side apply is
+// simulated and deployments are reported as rolled out as soon as they are updated
+func newFakeOperator(kubeObjects []runtime.Object, dynObjects ...runtime.Object) (*BaseOperator, *kubefake.Clientset, *dynamicfake.FakeDynamicClient) {
+	kubeClient := kubefake.NewSimpleClientset(kubeObjects...)
+	kubeClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			{Name: "serviceaccounts", Kind: "ServiceAccount", Namespaced: true},
+		},
+	}, {
+		GroupVersion: "apiextensions.k8s.io/v1",
+		APIResources: []metav1.APIResource{{Name: "customresourcedefinitions", Kind: "CustomResourceDefinition"}},
+	}, {
+		GroupVersion: "rbac.authorization.k8s.io/v1",
+		APIResources: []metav1.APIResource{{Name: "roles", Kind: "Role", Namespaced: true}},
+	}}
+	kubeClient.PrependReactor("update", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deployment := action.(k8stesting.UpdateAction).GetObject().(*appsv1.Deployment)
+		deployment.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+		return false, nil, nil
+	})
+
+	dynClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), dynObjects...)
+	dynClient.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		tracker := dynClient.Tracker()
+		if _, err := tracker.Get(patch.GetResource(), patch.GetNamespace(), obj.GetName()); apierrors.IsNotFound(err) {
+			return true, obj, tracker.Create(patch.GetResource(), obj, patch.GetNamespace())
+		}
+		return true, obj, tracker.Update(patch.GetResource(), obj, patch.GetNamespace())
+	})
+
+	b := &BaseOperator{
+		namespace:        "upgrade-test",
+		operatorName:     "test-operator",
+		kubeClient:       kubeClient,
+		dynClient:        dynClient,
+		deploymentConfig: appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test-operator"}},
+	}
+	return b, kubeClient, dynClient
+}
+
+func newFakeDeployment(namespace, name, image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "operator", Image: image}},
+		}}},
+		Status: appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+}
+
+func deploymentImage(t *testing.T, b *BaseOperator) string {
+	deployment, err := b.GetDeployment()
+	if err != nil {
+		t.Fatalf("unable to get deployment: %v", err)
+	}
+	return deployment.Spec.Template.Spec.Containers[0].Image
+}
+
+// TestUpgradeYamls validates that new operator resources are applied (and tracked)
+// and the deployment replaced, when upgrading from YAMLs
+func TestUpgradeYamls(t *testing.T) {
+	b, _, dynClient := newFakeOperator([]runtime.Object{newFakeDeployment("upgrade-test", "test-operator", "operator:1.0")})
+	// The image given to the builder must not override the upgraded deployment
+	b.image = "operator:1.0"
+	tracker := &fakeTracker{}
+	b.tracker = tracker
+
+	err := b.Upgrade(OperatorUpgrade{Yamls: [][]byte{[]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test-operator-config
+data:
+  version: "2.0"
+`), []byte(`
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: test-operator-v2
+rules: []
+`), []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test-operator-v2
+spec:
+  template:
+    spec:
+      containers:
+      - name: operator
+        image: operator:2.0
+`)}})
+	if err != nil {
+		t.Fatalf("unexpected error upgrading: %v", err)
+	}
+	if image := deploymentImage(t, b); image != "operator:2.0" || b.Image() != "operator:2.0" {
+		t.Errorf("deployment not upgraded, image: %s (operator image: %s)", image, b.Image())
+	}
+	if strings.Join(tracker.each, ",") != "configmaps/test-operator-config,roles/test-operator-v2" {
+		t.Errorf("unexpected resources tracked: %v", tracker.each)
+	}
+	configMap, err := dynClient.Resource(configMapGVR).Namespace("upgrade-test").Get(context.Background(), "test-operator-config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("config map not applied: %v", err)
+	}
+	if version, _, _ := unstructured.NestedString(configMap.Object, "data", "version"); version != "2.0" {
+		t.Errorf("unexpected config map version: %s", version)
+	}
+}
+
+// TestUpgradeImage validates that only the image is replaced when no YAMLs are
+// given, and that transient errors are retried while waiting for the rollout
+func TestUpgradeImage(t *testing.T) {
+	defer func(interval time.Duration) { UpgradeRetryInterval = interval }(UpgradeRetryInterval)
+	UpgradeRetryInterval = 10 * time.Millisecond
+
+	b, kubeClient, _ := newFakeOperator([]runtime.Object{newFakeDeployment("upgrade-test", "test-operator", "operator:1.0")})
+	// The deployment is briefly reported as missing once updated
+	updated, failures := false, 0
+	kubeClient.PrependReactor("update", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		updated = true
+		return false, nil, nil
+	})
+	kubeClient.PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if updated && failures < 2 {
+			failures++
+			return true, nil, apierrors.NewNotFound(appsv1.Resource("deployments"), "test-operator")
+		}
+		return false, nil, nil
+	})
+
+	if err := b.Upgrade(OperatorUpgrade{Image: "operator:2.0"}); err != nil {
+		t.Fatalf("unexpected error upgrading: %v", err)
+	}
+	if failures != 2 {
+		t.Errorf("transient errors not retried, failures: %d", failures)
+	}
+	if image := deploymentImage(t, b); image != "operator:2.0" {
+		t.Errorf("deployment not upgraded, image: %s", image)
+	}
+}
+
+// TestUpgradeOLM validates that the subscription channel is switched and the
+// new CSV awaited, when upgrading an operator installed through OLM
+func TestUpgradeOLM(t *testing.T) {
+	b, dynClient := newFakeOLM(OLMConfig{
+		Package:          "test-operator",
+		Channel:          "stable",
+		CatalogSource:    "test-catalog",
+		CatalogNamespace: "olm",
+	}, "test-operator.v1.0.0", "", newFakeCSV("test-operator.v1.0.0", "Succeeded"), newFakeCSV("test-operator.v2.0.0", "Succeeded"))
+	b.kubeClient = kubefake.NewSimpleClientset(newFakeDeployment("olm-test", "test-operator-controller", "operator:1.0"))
+	if err := b.SetupOLMWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error setting up: %v", err)
+	}
+
+	// Switching channel resolves to the new CSV
+	dynClient.PrependReactor("update", "subscriptions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		subscription := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		_ = unstructured.SetNestedField(subscription.Object, "test-operator.v2.0.0", "status", "currentCSV")
+		return false, nil, nil
+	})
+	if err := b.Upgrade(OperatorUpgrade{Channel: "fast"}); err != nil {
+		t.Fatalf("unexpected error upgrading: %v", err)
+	}
+	if b.InstalledCSV() != "test-operator.v2.0.0" {
+		t.Errorf("unexpected csv after upgrade: %s", b.InstalledCSV())
+	}
+	subscription, err := dynClient.Resource(subscriptionGVR).Namespace("olm-test").Get(context.Background(), "test-operator", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unable to get subscription: %v", err)
+	}
+	if channel, _, _ := unstructured.NestedString(subscription.Object, "spec", "channel"); channel != "fast" {
+		t.Errorf("subscription channel not switched: %s", channel)
+	}
+}
+
+func TestSkupperUpgrade(t *testing.T) {
+	s := &SkupperOperator{}
+	if err := s.Upgrade(OperatorUpgrade{Image: "skupper:latest"}); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("expected upgrade not to be supported, got: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package framework
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// contextLogger buffers the messages logged by a task running on a given
+// context, so that the output of tasks running concurrently is not interleaved
+type contextLogger struct {
+	id       string
+	mutex    sync.Mutex
+	messages []string
+}
+
+// Logf buffers the given message till the logger is flushed
+func (l *contextLogger) Logf(format string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func (l *contextLogger) flush() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, msg := range l.messages {
+		log.Logf("[%s] %s", l.id, msg)
+	}
+	l.messages = nil
+}
+
+// runConcurrently runs fn for each of the given ids concurrently, waiting for all
+// of them to complete. Messages logged through the provided contextLogger are
+// flushed in the order of the sorted ids and errors are aggregated in that same order.
+func runConcurrently(ids []string, fn func(id string, logger *contextLogger) error) error {
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+
+	loggers := make([]*contextLogger, len(sorted))
+	errs := make([]error, len(sorted))
+	var wg sync.WaitGroup
+	for i, id := range sorted {
+		loggers[i] = &contextLogger{id: id}
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("context %s: panic: %v", id, r)
+				}
+			}()
+			if err := fn(id, loggers[i]); err != nil {
+				errs[i] = fmt.Errorf("context %s: %v", id, err)
+			}
+		}(i, id)
+	}
+	wg.Wait()
+
+	for _, logger := range loggers {
+		logger.flush()
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// forEachContext runs fn concurrently for each context from the ContextMap
+func (f *Framework) forEachContext(fn func(ctxData *ContextData, logger *contextLogger) error) error {
+	var ids []string
+	for id := range f.ContextMap {
+		ids = append(ids, id)
+	}
+	return runConcurrently(ids, func(id string, logger *contextLogger) error {
+		return fn(f.ContextMap[id], logger)
+	})
+}
+
+// forEachOperator runs fn concurrently for each operator of the given context,
+// waiting for all of them to complete and returning the aggregated errors
+func (c *ContextData) forEachOperator(fn func(operator operators.OperatorSetup) error) error {
+	var operatorTypes []operators.OperatorType
+	for operatorType := range c.OperatorMap {
+		operatorTypes = append(operatorTypes, operatorType)
+	}
+	sort.Slice(operatorTypes, func(i, j int) bool {
+		return operatorTypes[i] < operatorTypes[j]
+	})
+
+	errs := make([]error, len(operatorTypes))
+	var wg sync.WaitGroup
+	for i, operatorType := range operatorTypes {
+		wg.Add(1)
+		go func(i int, operator operators.OperatorSetup) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("operator %s: panic: %v", operator.Name(), r)
+				}
+			}()
+			errs[i] = fn(operator)
+		}(i, c.OperatorMap[operatorType])
+	}
+	wg.Wait()
+	return utilerrors.NewAggregate(errs)
+}
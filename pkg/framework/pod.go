@@ -187,9 +187,13 @@ func (cb *ContainerBuilder) Build() v1.Container {
 }
 
 func (c *ContextData) GetPodNameFromNamespace(label string, ns string) (string, error) {
+	return c.GetPodNameFromNamespaceWithContext(context.TODO(), label, ns)
+}
+
+func (c *ContextData) GetPodNameFromNamespaceWithContext(ctx context.Context, label string, ns string) (string, error) {
 	podListOpts := metav1.ListOptions{}
 	podListOpts.LabelSelector = "name=" + label
-	podList, err := c.Clients.KubeClient.CoreV1().Pods(ns).List(context.TODO(), podListOpts)
+	podList, err := c.Clients.KubeClient.CoreV1().Pods(ns).List(ctx, podListOpts)
 	if err != nil {
 		return "", err
 	}
@@ -208,11 +212,19 @@ func (c *ContextData) GetPodName(label string) (string, error) {
 	return c.GetPodNameFromNamespace(label, c.Namespace)
 }
 
+func (c *ContextData) GetPodNameWithContext(ctx context.Context, label string) (string, error) {
+	return c.GetPodNameFromNamespaceWithContext(ctx, label, c.Namespace)
+}
+
 // returns whole pod log as a (meaty) string
 func (c *ContextData) GetLogsFromNamespace(podName string, ns string) (string, error) {
+	return c.GetLogsFromNamespaceWithContext(context.TODO(), podName, ns)
+}
+
+func (c *ContextData) GetLogsFromNamespaceWithContext(ctx context.Context, podName string, ns string) (string, error) {
 	podLogOpts := v1.PodLogOptions{}
 	request := c.Clients.KubeClient.CoreV1().Pods(ns).GetLogs(podName, &podLogOpts)
-	podLogs, err := request.Stream(context.TODO())
+	podLogs, err := request.Stream(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -229,13 +241,22 @@ func (c *ContextData) GetLogs(podName string) (string, error) {
 	return c.GetLogsFromNamespace(podName, c.Namespace)
 }
 
+func (c *ContextData) GetLogsWithContext(ctx context.Context, podName string) (string, error) {
+	return c.GetLogsFromNamespaceWithContext(ctx, podName, c.Namespace)
+}
+
 func (c *ContextData) WaitForPodStatus(podName string, status v1.PodPhase, timeout time.Duration, interval time.Duration) (*v1.Pod, error) {
-	var pod *v1.Pod
-	var err error
 	ctx, cancel := context.WithTimeout(context.TODO(), timeout)
 	defer cancel()
+	return c.WaitForPodStatusWithContext(ctx, podName, status, interval)
+}
+
+// WaitForPodStatusWithContext waits till the given pod reaches the expected phase or till the context is done
+func (c *ContextData) WaitForPodStatusWithContext(ctx context.Context, podName string, status v1.PodPhase, interval time.Duration) (*v1.Pod, error) {
+	var pod *v1.Pod
+	var err error
 	err = RetryWithContext(ctx, interval, func() (bool, error) {
-		pod, err = c.Clients.KubeClient.CoreV1().Pods(c.Namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+		pod, err = c.Clients.KubeClient.CoreV1().Pods(c.Namespace).Get(ctx, podName, metav1.GetOptions{})
 		if err != nil {
 			// pod does not exist yet
 			return false, nil
@@ -247,8 +268,29 @@ func (c *ContextData) WaitForPodStatus(podName string, status v1.PodPhase, timeo
 }
 
 func (f *Framework) Execute(ctx1 *ContextData, command string, arguments []string, podname string) (string, string, error) {
-	pod, err := ctx1.Clients.KubeClient.CoreV1().Pods(ctx1.Namespace).Get(context.TODO(), podname, metav1.GetOptions{})
-	request := ctx1.Clients.KubeClient.CoreV1().RESTClient().
+	return ctx1.ExecuteWithContext(context.TODO(), command, arguments, podname)
+}
+
+// ExecuteWithContext runs the given command in the provided pod, returning its standard output
+// and error. If the context is done before the command completes, the context error is returned,
+// while the remote command is left to finish on its own.
+func (f *Framework) ExecuteWithContext(ctx context.Context, ctx1 *ContextData, command string, arguments []string, podname string) (string, string, error) {
+	return ctx1.ExecuteWithContext(ctx, command, arguments, podname)
+}
+
+func (c *ContextData) Execute(command string, arguments []string, podname string) (string, string, error) {
+	return c.ExecuteWithContext(context.TODO(), command, arguments, podname)
+}
+
+// ExecuteWithContext runs the given command in the provided pod from this context's namespace,
+// returning its standard output and error. If the context is done before the command completes,
+// the context error is returned, while the remote command is left to finish on its own.
+func (c *ContextData) ExecuteWithContext(ctx context.Context, command string, arguments []string, podname string) (string, string, error) {
+	pod, err := c.Clients.KubeClient.CoreV1().Pods(c.Namespace).Get(ctx, podname, metav1.GetOptions{})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Failed retrieving pod %v/%v", c.Namespace, podname)
+	}
+	request := c.Clients.KubeClient.CoreV1().RESTClient().
 		Post().
 		Namespace(pod.Namespace).
 		Resource("pods").
@@ -261,13 +303,28 @@ func (f *Framework) Execute(ctx1 *ContextData, command string, arguments []strin
 			Stderr:  true,
 			TTY:     true,
 		}, scheme.ParameterCodec)
-	exec, err := remotecommand.NewSPDYExecutor(&f.restConfig, "POST", request.URL())
+	config, err := c.spdyConfig()
+	if err != nil {
+		return "", "", err
+	}
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", request.URL())
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Failed executing command %s on %v/%v", command, pod.Namespace, pod.Name)
+	}
 	buf := &bytes.Buffer{}
 	errBuf := &bytes.Buffer{}
-	err = exec.Stream(remotecommand.StreamOptions{
-		Stdout: buf,
-		Stderr: errBuf,
-	})
+	done := make(chan error, 1)
+	go func() {
+		done <- exec.Stream(remotecommand.StreamOptions{
+			Stdout: buf,
+			Stderr: errBuf,
+		})
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		return "", "", errors.Wrapf(ctx.Err(), "Failed executing command %s on %v/%v", command, pod.Namespace, pod.Name)
+	}
 	if err != nil {
 		return "", "", errors.Wrapf(err, "Failed executing command %s on %v/%v", command, pod.Namespace, pod.Name)
 	}
@@ -0,0 +1,100 @@
+package framework
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	portForwardReadyTimeout = 30 * time.Second
+)
+
+// PortForwarder holds a port-forward tunnel from a local port
+// to a port of a running pod
+type PortForwarder struct {
+	PodName    string
+	LocalPort  int
+	RemotePort int
+	stopCh     chan struct{}
+	closeOnce  sync.Once
+}
+
+// Address returns the local host:port that reaches the remote pod port
+func (p *PortForwarder) Address() string {
+	return fmt.Sprintf("127.0.0.1:%d", p.LocalPort)
+}
+
+// Close stops forwarding the port. It is safe to call it more than once.
+func (p *PortForwarder) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stopCh)
+	})
+}
+
+// PortForward opens a tunnel from a random local port to the given port
+// of the provided pod, running in the given context's namespace.
+// The returned PortForwarder must be closed when no longer needed.
+func (f *Framework) PortForward(ctx *ContextData, podName string, remotePort int) (*PortForwarder, error) {
+	return ctx.PortForward(podName, remotePort)
+}
+
+// PortForward opens a tunnel from a random local port to the given port of the
+// provided pod, running in this context's namespace.
+// The returned PortForwarder must be closed when no longer needed.
+func (c *ContextData) PortForward(podName string, remotePort int) (*PortForwarder, error) {
+	config, err := c.spdyConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+	request := c.Clients.KubeClient.CoreV1().RESTClient().
+		Post().
+		Namespace(c.Namespace).
+		Resource("pods").
+		Name(podName).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", request.URL())
+
+	pf := &PortForwarder{
+		PodName:    podName,
+		RemotePort: remotePort,
+		stopCh:     make(chan struct{}),
+	}
+	readyCh := make(chan struct{})
+	ports := []string{fmt.Sprintf("0:%d", remotePort)}
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, ports, pf.stopCh, readyCh, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return nil, err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case <-readyCh:
+	case err = <-errCh:
+		return nil, fmt.Errorf("error forwarding port %d from pod %s: %v", remotePort, podName, err)
+	case <-time.After(portForwardReadyTimeout):
+		pf.Close()
+		return nil, fmt.Errorf("timed out forwarding port %d from pod %s", remotePort, podName)
+	}
+
+	forwardedPorts, err := forwarder.GetPorts()
+	if err != nil {
+		pf.Close()
+		return nil, err
+	}
+	pf.LocalPort = int(forwardedPorts[0].Local)
+	return pf, nil
+}
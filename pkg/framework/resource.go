@@ -39,41 +39,84 @@ var (
 
 // GetResource returns the given resource type, identified by its given name
 func (c *ContextData) GetResource(resourceType ResourceType, name string) (*unstructured.Unstructured, error) {
-	return c.Clients.DynClient.Resource(resourceMap[resourceType]).Namespace(c.Namespace).Get(context.TODO(), name, v1.GetOptions{})
+	return c.GetResourceWithContext(context.TODO(), resourceType, name)
+}
+func (c *ContextData) GetResourceWithContext(ctx context.Context, resourceType ResourceType, name string) (*unstructured.Unstructured, error) {
+	return c.GetResourceGroupVersionWithContext(ctx, resourceMap[resourceType], name)
 }
 func (c *ContextData) GetResourceGroupVersion(gv schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
-	return c.Clients.DynClient.Resource(gv).Namespace(c.Namespace).Get(context.TODO(), name, v1.GetOptions{})
+	return c.GetResourceGroupVersionWithContext(context.TODO(), gv, name)
+}
+func (c *ContextData) GetResourceGroupVersionWithContext(ctx context.Context, gv schema.GroupVersionResource, name string) (*unstructured.Unstructured, error) {
+	return c.Clients.DynClient.Resource(gv).Namespace(c.Namespace).Get(ctx, name, v1.GetOptions{})
 }
 
 // ListResources returns a list of resources found in the related Framework's namespace,
 // for the given resource type
 func (c *ContextData) ListResources(resourceType ResourceType) (*unstructured.UnstructuredList, error) {
-	return c.Clients.DynClient.Resource(resourceMap[resourceType]).Namespace(c.Namespace).List(context.TODO(), v1.ListOptions{})
+	return c.ListResourcesWithContext(context.TODO(), resourceType)
+}
+func (c *ContextData) ListResourcesWithContext(ctx context.Context, resourceType ResourceType) (*unstructured.UnstructuredList, error) {
+	return c.ListResourcesGroupVersionWithContext(ctx, resourceMap[resourceType])
 }
 func (c *ContextData) ListResourcesGroupVersion(gv schema.GroupVersionResource) (*unstructured.UnstructuredList, error) {
-	return c.Clients.DynClient.Resource(gv).Namespace(c.Namespace).List(context.TODO(), v1.ListOptions{})
+	return c.ListResourcesGroupVersionWithContext(context.TODO(), gv)
+}
+func (c *ContextData) ListResourcesGroupVersionWithContext(ctx context.Context, gv schema.GroupVersionResource) (*unstructured.UnstructuredList, error) {
+	return c.Clients.DynClient.Resource(gv).Namespace(c.Namespace).List(ctx, v1.ListOptions{})
 }
 
 // CreateResource creates a resource based on provided (known) resource type and unstructured data
 func (c *ContextData) CreateResource(resourceType ResourceType, obj *unstructured.Unstructured, options v1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
-	return c.Clients.DynClient.Resource(resourceMap[resourceType]).Namespace(c.Namespace).Create(context.TODO(), obj, options, subresources...)
+	return c.CreateResourceWithContext(context.TODO(), resourceType, obj, options, subresources...)
+}
+func (c *ContextData) CreateResourceWithContext(ctx context.Context, resourceType ResourceType, obj *unstructured.Unstructured, options v1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return c.CreateResourceGroupVersionWithContext(ctx, resourceMap[resourceType], obj, options, subresources...)
 }
 func (c *ContextData) CreateResourceGroupVersion(gv schema.GroupVersionResource, obj *unstructured.Unstructured, options v1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
-	return c.Clients.DynClient.Resource(gv).Namespace(c.Namespace).Create(context.TODO(), obj, options, subresources...)
+	return c.CreateResourceGroupVersionWithContext(context.TODO(), gv, obj, options, subresources...)
+}
+func (c *ContextData) CreateResourceGroupVersionWithContext(ctx context.Context, gv schema.GroupVersionResource, obj *unstructured.Unstructured, options v1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	created, err := c.Clients.DynClient.Resource(gv).Namespace(c.Namespace).Create(ctx, obj, options, subresources...)
+	if err == nil && len(subresources) == 0 {
+		c.track(gv, created.GetNamespace(), created.GetName())
+	}
+	return created, err
+}
+
+// track records the given resource into the context's tracker (if any)
+func (c *ContextData) track(gvr schema.GroupVersionResource, namespace, name string) {
+	if c.Tracker != nil {
+		c.Tracker.Track(gvr, namespace, name)
+	}
 }
 
 // DeleteResource deletes a resource based on provided (known) resource type and name
 func (c *ContextData) DeleteResource(resourceType ResourceType, name string, options v1.DeleteOptions, subresources ...string) error {
-	return c.Clients.DynClient.Resource(resourceMap[resourceType]).Namespace(c.Namespace).Delete(context.TODO(), name, options, subresources...)
+	return c.DeleteResourceWithContext(context.TODO(), resourceType, name, options, subresources...)
+}
+func (c *ContextData) DeleteResourceWithContext(ctx context.Context, resourceType ResourceType, name string, options v1.DeleteOptions, subresources ...string) error {
+	return c.DeleteResourceGroupVersionWithContext(ctx, resourceMap[resourceType], name, options, subresources...)
 }
 func (c *ContextData) DeleteResourceGroupVersion(gv schema.GroupVersionResource, name string, options v1.DeleteOptions, subresources ...string) error {
-	return c.Clients.DynClient.Resource(gv).Namespace(c.Namespace).Delete(context.TODO(), name, options, subresources...)
+	return c.DeleteResourceGroupVersionWithContext(context.TODO(), gv, name, options, subresources...)
+}
+func (c *ContextData) DeleteResourceGroupVersionWithContext(ctx context.Context, gv schema.GroupVersionResource, name string, options v1.DeleteOptions, subresources ...string) error {
+	return c.Clients.DynClient.Resource(gv).Namespace(c.Namespace).Delete(ctx, name, options, subresources...)
 }
 
 func LoadYamlFromUrl(url string) (*unstructured.Unstructured, error) {
+	return LoadYamlFromUrlWithContext(context.TODO(), url)
+}
+
+func LoadYamlFromUrlWithContext(ctx context.Context, url string) (*unstructured.Unstructured, error) {
 	var unsObj unstructured.Unstructured
 
-	resp, err := http.Get(url) //load yaml body from url
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req) //load yaml body from url
 	if err != nil {
 		log.Logf("error during loading %s: %v", url, err)
 		return nil, err
@@ -23,16 +23,25 @@ import (
 )
 
 func (c *ContextData) GetService(name string) (*corev1.Service, error) {
-	return c.Clients.KubeClient.CoreV1().Services(c.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	return c.GetServiceWithContext(context.TODO(), name)
+}
+
+func (c *ContextData) GetServiceWithContext(ctx context.Context, name string) (*corev1.Service, error) {
+	return c.Clients.KubeClient.CoreV1().Services(c.Namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
 func (c *ContextData) WaitForService(name string, timeout time.Duration, interval time.Duration) (*corev1.Service, error) {
-	var service *corev1.Service
-	var err error
 	ctx, cancel := context.WithTimeout(context.TODO(), timeout)
 	defer cancel()
+	return c.WaitForServiceWithContext(ctx, name, interval)
+}
+
+// WaitForServiceWithContext waits till the given service exists or till the context is done
+func (c *ContextData) WaitForServiceWithContext(ctx context.Context, name string, interval time.Duration) (*corev1.Service, error) {
+	var service *corev1.Service
+	var err error
 	err = RetryWithContext(ctx, interval, func() (bool, error) {
-		service, err = c.GetService(name)
+		service, err = c.GetServiceWithContext(ctx, name)
 		if err != nil {
 			// service does not exist yet
 			return false, nil
@@ -172,8 +172,21 @@ func AfterReadingAllFlags(t *TestContextType) {
 // GetContexts returns a list of contexts from provided flags or the current-context if none.
 // If KubeConfig not provided or not generated, it returns nil.
 func (t TestContextType) GetContexts() []string {
+	contexts, err := t.LoadContexts()
+	if err == errNoCurrentContext {
+		panic(fmt.Sprintf("No context provided and current-context not defined in KUBECONFIG file: %s", t.KubeConfig))
+	}
+	gomega.Expect(err).To(gomega.BeNil())
+	return contexts
+}
+
+var errNoCurrentContext = fmt.Errorf("no context provided and current-context not defined")
+
+// LoadContexts returns a list of contexts from provided flags or the current-context if none,
+// returning an error if the KubeConfig cannot be loaded or has no current-context defined.
+func (t TestContextType) LoadContexts() ([]string, error) {
 	if len(t.KubeContexts) > 0 {
-		return t.KubeContexts
+		return t.KubeContexts, nil
 	}
 
 	if len(t.KubeConfig) == 0 {
@@ -181,13 +194,14 @@ func (t TestContextType) GetContexts() []string {
 	}
 
 	kubeConfig, err := clientcmd.LoadFromFile(t.KubeConfig)
-	gomega.Expect(err).To(gomega.BeNil())
+	if err != nil {
+		return nil, err
+	}
 
 	if kubeConfig.CurrentContext == "" {
-		panic(fmt.Sprintf("No context provided and current-context not defined in KUBECONFIG file: %s", t.KubeConfig))
+		return nil, errNoCurrentContext
 	}
-	return []string{kubeConfig.CurrentContext}
-
+	return []string{kubeConfig.CurrentContext}, nil
 }
 
 // ContextsAvailable returns the number of contexts available after
@@ -0,0 +1,191 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	// TrackerTimeout is the maximum amount of time to wait for each tracked
+	// resource to be removed (including the time taken by its finalizers)
+	TrackerTimeout = 2 * time.Minute
+)
+
+// TrackedResource identifies a resource recorded by a ResourceTracker.
+// Namespace is empty for cluster scoped resources.
+type TrackedResource struct {
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+}
+
+func (r TrackedResource) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", r.GVR.GroupResource(), r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", r.GVR.GroupResource(), r.Namespace, r.Name)
+}
+
+// ResourceTracker records the resources created through the framework helpers
+// of a context, so they can be removed (in reverse order of creation) when the
+// Framework runs AfterEach, or AfterSuite for the ones tracked for the suite.
+// This covers cluster scoped resources and resources from other namespaces,
+// which are not removed along with the namespace of the context.
+// Cleanup functions can be registered as well, for anything else to be undone
+// when the Framework runs AfterEach.
+type ResourceTracker struct {
+	client   dynamic.Interface
+	lock     sync.Mutex
+	each     []TrackedResource
+	suite    []TrackedResource
+	cleanups []func(ctx context.Context) error
+}
+
+func newResourceTracker(client dynamic.Interface) *ResourceTracker {
+	return &ResourceTracker{client: client}
+}
+
+// Track records a resource to be removed when the Framework runs AfterEach
+func (t *ResourceTracker) Track(gvr schema.GroupVersionResource, namespace, name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.each = append(t.each, TrackedResource{GVR: gvr, Namespace: namespace, Name: name})
+}
+
+// TrackForSuite records a resource to be removed when the Framework runs AfterSuite,
+// for resources shared by all specs of the suite
+func (t *ResourceTracker) TrackForSuite(gvr schema.GroupVersionResource, namespace, name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.suite = append(t.suite, TrackedResource{GVR: gvr, Namespace: namespace, Name: name})
+}
+
+// AddCleanup registers a function to be called when the Framework runs AfterEach,
+// before the tracked resources are removed
+func (t *ResourceTracker) AddCleanup(fn func(ctx context.Context) error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.cleanups = append(t.cleanups, fn)
+}
+
+// Untrack stops tracking the given resource, so it is not removed by the tracker
+func (t *ResourceTracker) Untrack(gvr schema.GroupVersionResource, namespace, name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	resource := TrackedResource{GVR: gvr, Namespace: namespace, Name: name}
+	t.each = removeTracked(t.each, resource)
+	t.suite = removeTracked(t.suite, resource)
+}
+
+// Tracked returns the resources to be removed when the Framework runs AfterEach
+func (t *ResourceTracker) Tracked() []TrackedResource {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]TrackedResource{}, t.each...)
+}
+
+func removeTracked(resources []TrackedResource, resource TrackedResource) []TrackedResource {
+	var kept []TrackedResource
+	for _, r := range resources {
+		if r != resource {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// deleteEach calls the registered cleanup functions, then removes (and stops tracking)
+// the resources tracked for AfterEach. When preserveNamespaced is true, namespaced
+// resources are kept along with their namespaces, so only cluster scoped resources are removed.
+func (t *ResourceTracker) deleteEach(ctx context.Context, preserveNamespaced bool) error {
+	t.lock.Lock()
+	resources := t.each
+	cleanups := t.cleanups
+	t.each = nil
+	t.cleanups = nil
+	t.lock.Unlock()
+
+	var errs []error
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := cleanups[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if preserveNamespaced {
+		var clusterScoped []TrackedResource
+		for _, resource := range resources {
+			if resource.Namespace == "" {
+				clusterScoped = append(clusterScoped, resource)
+			} else {
+				log.Logf("Preserving %s", resource)
+			}
+		}
+		resources = clusterScoped
+	}
+	return utilerrors.NewAggregate(append(errs, t.delete(ctx, resources)))
+}
+
+// deleteSuite removes (and stops tracking) all tracked resources, including the
+// ones tracked for AfterEach that have not been removed yet
+func (t *ResourceTracker) deleteSuite(ctx context.Context) error {
+	t.lock.Lock()
+	resources := append(t.suite, t.each...)
+	t.each = nil
+	t.suite = nil
+	t.lock.Unlock()
+	return t.delete(ctx, resources)
+}
+
+// delete removes the given resources in reverse order, waiting for each of them
+// to be gone before moving on, as resources created later may depend on earlier ones
+// (like custom resources and their CRDs). Resources that are still present after
+// TrackerTimeout, usually held by finalizers, are reported as stuck.
+func (t *ResourceTracker) delete(ctx context.Context, resources []TrackedResource) error {
+	var errs []error
+	var stuck []string
+	propagation := metav1.DeletePropagationBackground
+	for i := len(resources) - 1; i >= 0; i-- {
+		resource := resources[i]
+		client := t.client.Resource(resource.GVR).Namespace(resource.Namespace)
+		err := client.Delete(ctx, resource.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("unable to delete %s: %v", resource, err))
+			continue
+		}
+		log.Logf("Deleted %s", resource)
+
+		var finalizers []string
+		err = wait.PollImmediateWithContext(ctx, CleanupRetryInterval, TrackerTimeout, func(ctx context.Context) (bool, error) {
+			current, err := client.Get(ctx, resource.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			} else if err != nil {
+				return false, err
+			}
+			finalizers = current.GetFinalizers()
+			return false, nil
+		})
+		if err == wait.ErrWaitTimeout {
+			stuck = append(stuck, fmt.Sprintf("%s (finalizers: [%s])", resource, strings.Join(finalizers, ", ")))
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("failed waiting for %s to be deleted: %v", resource, err))
+		}
+	}
+	if len(stuck) > 0 {
+		errs = append(errs, fmt.Errorf("resources not removed after %v: %s", TrackerTimeout, strings.Join(stuck, "; ")))
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,142 @@
+package framework_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newDeployment(name string, finalizers ...string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("apps/v1")
+	u.SetKind("Deployment")
+	u.SetName(name)
+	u.SetFinalizers(finalizers)
+	return u
+}
+
+// deleteNamespaces sets whether namespaces (and namespaced resources) are removed
+// after each spec, returning a function that restores the original setting
+func deleteNamespaces(enabled bool) func() {
+	deleteNamespace, deleteNamespaceOnFailure := framework.TestContext.DeleteNamespace, framework.TestContext.DeleteNamespaceOnFailure
+	framework.TestContext.DeleteNamespace, framework.TestContext.DeleteNamespaceOnFailure = enabled, enabled
+	return func() {
+		framework.TestContext.DeleteNamespace, framework.TestContext.DeleteNamespaceOnFailure = deleteNamespace, deleteNamespaceOnFailure
+	}
+}
+
+// TestResourceTracker validates that resources created through the framework
+// helpers are removed on AfterEach, in reverse order of creation
+func TestResourceTracker(t *testing.T) {
+	defer deleteNamespaces(true)()
+	ctx := context.Background()
+	f, err := framework.NewFrameworkBuilder("tracker").WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+	ctxData := f.GetFirstContext()
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	for _, name := range []string{"first", "second"} {
+		if _, err := ctxData.CreateResource(framework.Deployments, newDeployment(name), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("unable to create deployment %s: %v", name, err)
+		}
+	}
+	// Resources from other namespaces can be tracked explicitly
+	other, err := ctxData.Clients.DynClient.Resource(gvr).Namespace("other").Create(ctx, newDeployment("other"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unable to create deployment: %v", err)
+	}
+	ctxData.Tracker.Track(gvr, other.GetNamespace(), other.GetName())
+	if tracked := ctxData.Tracker.Tracked(); len(tracked) != 3 || tracked[0].Name != "first" {
+		t.Errorf("unexpected tracked resources: %v", tracked)
+	}
+
+	var deleted []string
+	ctxData.Clients.DynClient.(*dynamicfake.FakeDynamicClient).PrependReactor("delete", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleted = append(deleted, action.(k8stesting.DeleteAction).GetName())
+		return false, nil, nil
+	})
+	if err := f.AfterEachWithContext(ctx); err != nil {
+		t.Errorf("unexpected error on teardown: %v", err)
+	}
+	if strings.Join(deleted, ",") != "other,second,first" {
+		t.Errorf("unexpected deletion order: %v", deleted)
+	}
+	if len(ctxData.Tracker.Tracked()) != 0 {
+		t.Errorf("resources still tracked: %v", ctxData.Tracker.Tracked())
+	}
+}
+
+// TestResourceTrackerStuck validates that resources held by finalizers are reported
+func TestResourceTrackerStuck(t *testing.T) {
+	defer func(timeout time.Duration) { framework.TrackerTimeout = timeout }(framework.TrackerTimeout)
+	framework.TrackerTimeout = 100 * time.Millisecond
+	defer deleteNamespaces(true)()
+
+	ctx := context.Background()
+	f, err := framework.NewFrameworkBuilder("tracker").WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+	ctxData := f.GetFirstContext()
+	namespace := ctxData.Namespace
+	if _, err := ctxData.CreateResource(framework.Deployments, newDeployment("stuck", "example.com/finalizer"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unable to create deployment: %v", err)
+	}
+
+	// Deleting a resource with finalizers only marks it for deletion
+	ctxData.Clients.DynClient.(*dynamicfake.FakeDynamicClient).PrependReactor("delete", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	err = f.AfterEachWithContext(ctx)
+	if err == nil || !strings.Contains(err.Error(), "deployments.apps "+namespace+"/stuck (finalizers: [example.com/finalizer])") {
+		t.Errorf("expected stuck resource to be reported, got: %v", err)
+	}
+}
+
+// TestResourceTrackerPreserveNamespaces validates that namespaced resources are kept
+// along with their namespaces, while cluster scoped ones are still removed
+func TestResourceTrackerPreserveNamespaces(t *testing.T) {
+	defer deleteNamespaces(false)()
+
+	ctx := context.Background()
+	f, err := framework.NewFrameworkBuilder("tracker").WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+	ctxData := f.GetFirstContext()
+	if _, err := ctxData.CreateResource(framework.Deployments, newDeployment("kept"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unable to create deployment: %v", err)
+	}
+	gvr := schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+	role := &unstructured.Unstructured{}
+	role.SetAPIVersion("rbac.authorization.k8s.io/v1")
+	role.SetKind("ClusterRole")
+	role.SetName("removed")
+	if _, err := ctxData.Clients.DynClient.Resource(gvr).Create(ctx, role, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unable to create cluster role: %v", err)
+	}
+	ctxData.Tracker.Track(gvr, "", role.GetName())
+
+	var deleted []string
+	ctxData.Clients.DynClient.(*dynamicfake.FakeDynamicClient).PrependReactor("delete", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleted = append(deleted, action.(k8stesting.DeleteAction).GetName())
+		return false, nil, nil
+	})
+	if err := f.AfterEachWithContext(ctx); err != nil {
+		t.Errorf("unexpected error on teardown: %v", err)
+	}
+	if strings.Join(deleted, ",") != "removed" {
+		t.Errorf("unexpected deleted resources: %v", deleted)
+	}
+}
@@ -0,0 +1,359 @@
+package topology
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+	"github.com/onsi/gomega"
+	"github.com/rh-messaging/shipshape/pkg/api/interconnect/v1alpha1"
+	"github.com/rh-messaging/shipshape/pkg/apps/broker"
+	"github.com/rh-messaging/shipshape/pkg/apps/qdr"
+	"github.com/rh-messaging/shipshape/pkg/apps/skupper"
+	"github.com/rh-messaging/shipshape/pkg/framework"
+	"github.com/rh-messaging/shipshape/pkg/framework/log"
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+)
+
+// Load reads a topology from the given YAML file
+func Load(path string) (*Topology, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read topology %s: %v", path, err)
+	}
+	t, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid topology %s: %v", path, err)
+	}
+	return t, nil
+}
+
+// Parse parses and validates a topology from YAML (or JSON) data.
+// Unknown fields are reported as errors, to catch typos early.
+func Parse(data []byte) (*Topology, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	t := &Topology{}
+	if err := decoder.Decode(t); err != nil {
+		return nil, err
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Validate verifies that the topology is consistent
+func (t *Topology) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("topology name is required")
+	}
+	if len(t.Contexts) == 0 {
+		return fmt.Errorf("at least one context is required")
+	}
+	contexts := map[string]*Context{}
+	for i := range t.Contexts {
+		c := &t.Contexts[i]
+		if c.Name == "" {
+			return fmt.Errorf("context #%d has no name", i)
+		}
+		if _, found := contexts[c.Name]; found {
+			return fmt.Errorf("context %s defined more than once", c.Name)
+		}
+		contexts[c.Name] = c
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("context %s: %v", c.Name, err)
+		}
+	}
+	for _, link := range t.Links {
+		for _, name := range []string{link.From, link.To} {
+			c, found := contexts[name]
+			if !found {
+				return fmt.Errorf("link from %q to %q: unknown context %q", link.From, link.To, name)
+			}
+			if c.Site == nil {
+				return fmt.Errorf("link from %q to %q: context %s has no site", link.From, link.To, name)
+			}
+		}
+		if link.From == link.To {
+			return fmt.Errorf("link from %q to itself", link.From)
+		}
+	}
+	return nil
+}
+
+func (c *Context) validate() error {
+	listed := map[operators.OperatorType]bool{}
+	for _, operator := range c.Operators {
+		operatorType, err := operators.ParseOperatorType(operator.Type)
+		if err != nil {
+			return err
+		}
+		if listed[operatorType] {
+			return fmt.Errorf("operator %s listed more than once", operator.Type)
+		}
+		listed[operatorType] = true
+	}
+	names := map[string]bool{}
+	for _, b := range c.Brokers {
+		if b.Name == "" || names[b.Name] {
+			return fmt.Errorf("brokers and routers must have unique names, found: %q", b.Name)
+		}
+		names[b.Name] = true
+	}
+	for _, r := range c.Routers {
+		if r.Name == "" || names[r.Name] {
+			return fmt.Errorf("brokers and routers must have unique names, found: %q", r.Name)
+		}
+		names[r.Name] = true
+		switch v1alpha1.RouterRoleType(r.Role) {
+		case "", v1alpha1.RouterRoleInterior, v1alpha1.RouterRoleEdge:
+		default:
+			return fmt.Errorf("router %s: invalid role %q", r.Name, r.Role)
+		}
+	}
+	if c.Site != nil && !c.Site.Controller && len(c.Site.Options) > 0 {
+		return fmt.Errorf("site options are only supported with the site controller")
+	}
+	return nil
+}
+
+// ContextNames returns the names of the contexts, in the order they are defined
+func (t *Topology) ContextNames() []string {
+	var names []string
+	for _, c := range t.Contexts {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Builder returns a Framework builder for the topology contexts, with the operator
+// builders of each context customized as described by the topology. The returned
+// builder can be further customized before building the Framework.
+func (t *Topology) Builder() (framework.Builder, error) {
+	builder := framework.NewFrameworkBuilder(t.Name).
+		WithContexts(t.ContextNames()...).
+		IsOpenshift(t.OpenShift).
+		WithGlobalOperator(t.GlobalOperator)
+	for _, c := range t.Contexts {
+		builders, err := c.operatorBuilders()
+		if err != nil {
+			return builder, fmt.Errorf("context %s: %v", c.Name, err)
+		}
+		builder = builder.WithContextBuilders(c.Name, builders...)
+	}
+	return builder, nil
+}
+
+// operatorBuilders returns new builders for the listed operators, along with
+// the ones needed by the brokers, routers and site of the context
+func (c *Context) operatorBuilders() ([]operators.OperatorSetupBuilder, error) {
+	builders := map[operators.OperatorType]operators.OperatorSetupBuilder{}
+	var ordered []operators.OperatorSetupBuilder
+	add := func(operatorType operators.OperatorType) (operators.OperatorSetupBuilder, error) {
+		if builder, found := builders[operatorType]; found {
+			return builder, nil
+		}
+		builder, err := operators.NewOperatorBuilder(operatorType)
+		if err != nil {
+			return nil, err
+		}
+		builders[operatorType] = builder
+		ordered = append(ordered, builder)
+		return builder, nil
+	}
+
+	for _, operator := range c.Operators {
+		operatorType, err := operators.ParseOperatorType(operator.Type)
+		if err != nil {
+			return nil, err
+		}
+		builder, err := add(operatorType)
+		if err != nil {
+			return nil, err
+		}
+		operator.applyTo(builder)
+	}
+	if len(c.Brokers) > 0 {
+		if _, err := add(operators.OperatorTypeBroker); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.Routers) > 0 {
+		if _, err := add(operators.OperatorTypeQdr); err != nil {
+			return nil, err
+		}
+	}
+	if c.Site != nil {
+		builder, err := add(operators.OperatorTypeSkupper)
+		if err != nil {
+			return nil, err
+		}
+		c.Site.applyTo(builder.(*operators.SkupperOperatorBuilder))
+	}
+	return ordered, nil
+}
+
+func (o *Operator) applyTo(builder operators.OperatorSetupBuilder) {
+	if o.Name != "" {
+		builder.WithOperatorName(o.Name)
+	}
+	if o.Image != "" {
+		builder.WithImage(o.Image)
+	}
+	if o.Command != "" {
+		builder.WithCommand(o.Command)
+	}
+	if o.APIVersion != "" {
+		builder.WithApiVersion(o.APIVersion)
+	}
+	if len(o.YamlURLs) > 0 {
+		builder.WithYamlURLs(o.YamlURLs)
+	}
+	if o.KeepCRDs {
+		builder.KeepCdr(true)
+	}
+	if o.OLM != nil {
+		builder.WithOLM(*o.OLM)
+	}
+}
+
+func (s *Site) applyTo(builder *operators.SkupperOperatorBuilder) {
+	builder.ClusterLocal(s.ClusterLocal)
+	if s.SkupperPath != "" {
+		builder.WithSkupperPath(s.SkupperPath)
+	}
+	builder.WithQdrouterdImage(s.RouterImage)
+	builder.WithControllerImage(s.ControllerImage)
+	builder.WithProxyImage(s.ProxyImage)
+	if s.Controller {
+		builder.WithSiteController(s.SkupperSiteConfig)
+	} else {
+		builder.WithSiteConfig(s.SkupperSiteConfig)
+	}
+}
+
+// Build builds the Framework and provisions the topology, failing the running spec on errors
+func (t *Topology) Build() *framework.Framework {
+	f, err := t.BuildWithContext(context.TODO())
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	return f
+}
+
+// BuildWithContext builds the Framework and provisions the topology. If provisioning
+// fails, the Framework is torn down and the error is returned.
+func (t *Topology) BuildWithContext(ctx context.Context) (*framework.Framework, error) {
+	builder, err := t.Builder()
+	if err != nil {
+		return nil, err
+	}
+	f, err := builder.BuildWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.ProvisionWithContext(ctx, f); err != nil {
+		if cleanupErr := f.AfterEachWithContext(ctx); cleanupErr != nil {
+			log.Logf("error cleaning up after failed provisioning: %v", cleanupErr)
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (t *Topology) Provision(f *framework.Framework) error {
+	return t.ProvisionWithContext(context.TODO(), f)
+}
+
+// ProvisionWithContext creates the brokers and routers of each context on the given
+// Framework, waiting for them to be ready, then links the sites. Created resources
+// are tracked by their contexts and removed when the Framework runs AfterEach.
+func (t *Topology) ProvisionWithContext(ctx context.Context, f *framework.Framework) error {
+	for _, c := range t.Contexts {
+		ctxData, found := f.ContextMap[c.Name]
+		if !found {
+			return fmt.Errorf("context %s not available on framework", c.Name)
+		}
+		if err := c.provision(ctx, ctxData); err != nil {
+			return fmt.Errorf("context %s: %v", c.Name, err)
+		}
+	}
+	for _, link := range t.Links {
+		err := skupper.LinkSitesWithContext(ctx, f.ContextMap[link.From], f.ContextMap[link.To], skupper.LinkSitesOptions{
+			Name:      link.Name,
+			TokenType: link.TokenType,
+			Cost:      link.Cost,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to link %s to %s: %v", link.From, link.To, err)
+		}
+	}
+	return nil
+}
+
+// provision creates the brokers and routers on the given context and
+// waits for all of them to be ready
+func (c *Context) provision(ctx context.Context, ctxData *framework.ContextData) error {
+	brokers := broker.NewBroker(ctxData)
+	for _, b := range c.Brokers {
+		if _, err := brokers.CreateWithContext(ctx, b.build()); err != nil {
+			return fmt.Errorf("unable to create broker %s: %v", b.Name, err)
+		}
+	}
+
+	routers := qdr.NewQdr(ctxData)
+	for _, r := range c.Routers {
+		if _, err := routers.CreateWithContext(ctx, r.build()); err != nil {
+			return fmt.Errorf("unable to create router %s: %v", r.Name, err)
+		}
+	}
+
+	for _, b := range c.Brokers {
+		if err := brokers.WaitForReadyWithContext(ctx, b.Name, broker.Timeout); err != nil {
+			return err
+		}
+	}
+	for _, r := range c.Routers {
+		if err := routers.WaitForReadyWithContext(ctx, r.Name, qdr.Timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Broker) build() *broker.Artemis {
+	builder := broker.NewArtemisBuilder(b.Name).
+		Image(b.Image).
+		InitImage(b.InitImage).
+		RequireLogin(b.RequireLogin)
+	if b.Size > 0 {
+		builder.Size(b.Size)
+	}
+	if b.AdminUser != "" {
+		builder.AdminCredentials(b.AdminUser, b.AdminPassword)
+	}
+	if b.Clustered != nil {
+		builder.Clustered(*b.Clustered)
+	}
+	for _, acceptor := range b.Acceptors {
+		builder.AddAcceptor(acceptor.Name, acceptor.Port, acceptor.Protocols)
+	}
+	return builder.Build()
+}
+
+func (r *Router) build() *v1alpha1.Interconnect {
+	builder := qdr.NewInterconnectBuilder(r.Name).Image(r.Image)
+	if r.Size > 0 {
+		builder.Size(r.Size)
+	}
+	if v1alpha1.RouterRoleType(r.Role) == v1alpha1.RouterRoleEdge {
+		builder.Edge()
+	}
+	return builder.Build()
+}
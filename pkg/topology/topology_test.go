@@ -0,0 +1,98 @@
+package topology_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+	"github.com/rh-messaging/shipshape/pkg/topology"
+)
+
+func TestLoad(t *testing.T) {
+	topo, err := topology.Load("testdata/multicluster.yaml")
+	if err != nil {
+		t.Fatalf("unable to load topology: %v", err)
+	}
+	if strings.Join(topo.ContextNames(), ",") != "east,west" {
+		t.Errorf("unexpected contexts: %v", topo.ContextNames())
+	}
+	east, west := topo.Contexts[0], topo.Contexts[1]
+	if len(east.Brokers) != 1 || east.Brokers[0].Size != 2 || east.Brokers[0].Acceptors[0].Port != 5672 {
+		t.Errorf("unexpected brokers: %+v", east.Brokers)
+	}
+	if !east.Site.ClusterLocal || east.Site.Controller {
+		t.Errorf("unexpected east site: %+v", east.Site)
+	}
+	if !west.Site.Controller || !west.Site.Console || west.Site.RouterMode != "edge" || west.Site.Name != "west" {
+		t.Errorf("unexpected west site: %+v", west.Site)
+	}
+	if len(topo.Links) != 1 || topo.Links[0].From != "west" || topo.Links[0].Cost != 2 {
+		t.Errorf("unexpected links: %+v", topo.Links)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		yaml     string
+		expected string
+	}{
+		{"contexts: [{name: a}]", "name is required"},
+		{"name: x", "at least one context"},
+		{"name: x\ncontexts: [{name: a}, {name: a}]", "defined more than once"},
+		{"name: x\ncontexts: [{name: a, operators: [{type: unknown}]}]", "unsupported operator type"},
+		{"name: x\ncontexts: [{name: a, routers: [{name: r, role: hub}]}]", "invalid role"},
+		{"name: x\ncontexts: [{name: a, brokers: [{name: b}], routers: [{name: b}]}]", "unique names"},
+		{"name: x\ncontexts: [{name: a, site: {}}]\nlinks: [{from: a, to: b}]", "unknown context"},
+		{"name: x\ncontexts: [{name: a, site: {}}, {name: b}]\nlinks: [{from: a, to: b}]", "has no site"},
+		{"name: x\ncontexts: [{name: a, sizes: 1}]", "unknown field"},
+		{"name: x\ncontexts: [{name: a, site: {options: {service-sync: 'false'}}}]", "only supported with the site controller"},
+	} {
+		_, err := topology.Parse([]byte(tc.yaml))
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("parsing %q, expected error containing %q, got: %v", tc.yaml, tc.expected, err)
+		}
+	}
+}
+
+// TestBuilder validates that each context gets its own operators
+func TestBuilder(t *testing.T) {
+	topo, err := topology.Load("testdata/multicluster.yaml")
+	if err != nil {
+		t.Fatalf("unable to load topology: %v", err)
+	}
+	builder, err := topo.Builder()
+	if err != nil {
+		t.Fatalf("unable to create builder: %v", err)
+	}
+	ctx := context.Background()
+	f, err := builder.WithFakeClients().BuildWithContext(ctx)
+	if err != nil {
+		t.Fatalf("unable to build framework: %v", err)
+	}
+	defer func() {
+		if err := f.AfterEachWithContext(ctx); err != nil {
+			t.Errorf("unexpected error on teardown: %v", err)
+		}
+	}()
+
+	east, west := f.ContextMap["east"], f.ContextMap["west"]
+	if east == nil || west == nil {
+		t.Fatalf("contexts not available: %v", f.ContextMap)
+	}
+	if len(east.OperatorMap) != 2 || len(west.OperatorMap) != 2 {
+		t.Errorf("unexpected operators, east: %v, west: %v", east.OperatorMap, west.OperatorMap)
+	}
+	broker := east.OperatorMap[operators.OperatorTypeBroker]
+	if broker == nil || broker.Image() != "quay.io/artemiscloud/activemq-artemis-operator:1.0.4" {
+		t.Errorf("broker operator image not overridden: %v", broker)
+	}
+	if west.OperatorMap[operators.OperatorTypeQdr] == nil || west.OperatorMap[operators.OperatorTypeSkupper] == nil {
+		t.Errorf("expected qdr and skupper operators on west, got: %v", west.OperatorMap)
+	}
+
+	// Defaults shared by other frameworks must not be affected
+	if image := operators.SupportedOperators[operators.OperatorTypeBroker].(operators.OperatorBuilderInfo).OperatorImage(); image == broker.Image() {
+		t.Errorf("default broker operator image has been changed: %s", image)
+	}
+}
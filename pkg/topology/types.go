@@ -0,0 +1,119 @@
+package topology
+
+import (
+	"github.com/rh-messaging/shipshape/pkg/framework/operators"
+)
+
+// Topology describes the contexts a Framework runs on, the operators installed
+// on each of them and the brokers, routers and skupper sites to be created,
+// along with the links between sites. It is usually loaded from a YAML file:
+//
+//	name: multicluster
+//	contexts:
+//	- name: cluster1
+//	  operators:
+//	  - type: broker
+//	    image: quay.io/artemiscloud/activemq-artemis-operator:1.0.4
+//	    apiVersion: v1beta1
+//	  brokers:
+//	  - name: broker1
+//	    size: 2
+//	  site:
+//	    name: east
+//	- name: cluster2
+//	  routers:
+//	  - name: router1
+//	    role: edge
+//	  site:
+//	    name: west
+//	    controller: true
+//	links:
+//	- from: cluster2
+//	  to: cluster1
+type Topology struct {
+	// Name is used as the Framework base name
+	Name           string    `json:"name"`
+	OpenShift      bool      `json:"openshift,omitempty"`
+	GlobalOperator bool      `json:"globalOperator,omitempty"`
+	Contexts       []Context `json:"contexts"`
+	Links          []Link    `json:"links,omitempty"`
+}
+
+// Context describes what is installed on a kubeconfig context
+type Context struct {
+	// Name of the kubeconfig context
+	Name string `json:"name"`
+	// Operators to install. Operators needed by the brokers, routers and
+	// site of the context are installed with their defaults if not listed.
+	Operators []Operator `json:"operators,omitempty"`
+	Brokers   []Broker   `json:"brokers,omitempty"`
+	Routers   []Router   `json:"routers,omitempty"`
+	Site      *Site      `json:"site,omitempty"`
+}
+
+// Operator overrides the defaults of one of the supported operators
+type Operator struct {
+	// Type of the operator (qdr, broker or skupper)
+	Type       string   `json:"type"`
+	Name       string   `json:"name,omitempty"`
+	Image      string   `json:"image,omitempty"`
+	Command    string   `json:"command,omitempty"`
+	APIVersion string   `json:"apiVersion,omitempty"`
+	YamlURLs   []string `json:"yamlURLs,omitempty"`
+	KeepCRDs   bool     `json:"keepCRDs,omitempty"`
+	// OLM installs the operator through the Operator Lifecycle Manager
+	OLM *operators.OLMConfig `json:"olm,omitempty"`
+}
+
+// Broker describes an ActiveMQArtemis to create
+type Broker struct {
+	Name          string     `json:"name"`
+	Size          int32      `json:"size,omitempty"`
+	Image         string     `json:"image,omitempty"`
+	InitImage     string     `json:"initImage,omitempty"`
+	AdminUser     string     `json:"adminUser,omitempty"`
+	AdminPassword string     `json:"adminPassword,omitempty"`
+	RequireLogin  bool       `json:"requireLogin,omitempty"`
+	Clustered     *bool      `json:"clustered,omitempty"`
+	Acceptors     []Acceptor `json:"acceptors,omitempty"`
+}
+
+// Acceptor describes a broker acceptor
+type Acceptor struct {
+	Name      string `json:"name"`
+	Port      int32  `json:"port"`
+	Protocols string `json:"protocols,omitempty"`
+}
+
+// Router describes an Interconnect to create
+type Router struct {
+	Name string `json:"name"`
+	// Role is either interior (default) or edge
+	Role  string `json:"role,omitempty"`
+	Size  int32  `json:"size,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+// Site describes the skupper site initialized on the context, either through
+// the skupper CLI (default) or through the site controller. The site settings
+// are passed to "skupper init" or set on the skupper-site ConfigMap, respectively.
+type Site struct {
+	operators.SkupperSiteConfig
+	// Controller initializes the site through the site controller
+	Controller      bool   `json:"controller,omitempty"`
+	ClusterLocal    bool   `json:"clusterLocal,omitempty"`
+	SkupperPath     string `json:"skupperPath,omitempty"`
+	RouterImage     string `json:"routerImage,omitempty"`
+	ControllerImage string `json:"controllerImage,omitempty"`
+	ProxyImage      string `json:"proxyImage,omitempty"`
+}
+
+// Link describes a link from the site of a context to the site of another one
+type Link struct {
+	// From and To are context names
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Name      string `json:"name,omitempty"`
+	TokenType string `json:"tokenType,omitempty"`
+	Cost      int    `json:"cost,omitempty"`
+}
@@ -22,6 +22,14 @@ func Initialize(t *testing.T, uniqueId string, description string) {
 	os.Setenv("OPERATOR_TESTING", "true")
 }
 
+// CleanStart purges the namespaces left behind by previous runs on the given
+// contexts (or on all available contexts) when --clean-start is set.
+// Suites must call it from their own BeforeSuite, or from the node 1 function
+// of a SynchronizedBeforeSuite when running in parallel.
+func CleanStart(contexts ...string) {
+	framework.CleanStart(contexts...)
+}
+
 // After suite validation teardown (happens only once per test suite)
 var _ = ginkgo.SynchronizedAfterSuite(func() {
 	// All nodes tear down